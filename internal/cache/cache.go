@@ -1,7 +1,6 @@
 package cache
 
 import (
-	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -9,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
 
 	"github.com/jackh54/mcinit/internal/utils"
 )
@@ -25,7 +26,7 @@ type CacheMetadata struct {
 	ServerType  string `json:"serverType"`
 	DownloadURL string `json:"downloadUrl"`
 	Checksum    string `json:"checksum"`
-	Algorithm   string `json:"algorithm"` // "sha1" or "sha256"
+	Algorithm   string `json:"algorithm"` // "sha1", "sha256" or "md5"
 	CachedAt    string `json:"cachedAt"`
 }
 
@@ -159,25 +160,75 @@ func (c *Cache) SaveMetadata(serverType, version, build string, meta *CacheMetad
 
 // VerifyChecksum verifies the checksum of a cached jar
 func (c *Cache) VerifyChecksum(jarPath, expectedChecksum, algorithm string) (bool, error) {
-	file, err := os.Open(jarPath)
+	actualChecksum, err := FileChecksum(jarPath, algorithm)
 	if err != nil {
-		return false, fmt.Errorf("failed to open jar file: %w", err)
+		return false, err
+	}
+	return strings.EqualFold(actualChecksum, expectedChecksum), nil
+}
+
+// StoreJar copies a jar that was not downloaded, such as one built by
+// BuildTools, into the cache and records its SHA-256 checksum. It returns
+// the cached path.
+func (c *Cache) StoreJar(srcPath, serverType, version, build, sourceURL string) (string, error) {
+	if err := c.EnsureJarsDir(); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	checksum, err := FileChecksum(srcPath, "sha256")
+	if err != nil {
+		return "", err
+	}
+
+	jarPath := c.GetJarPath(serverType, version, build)
+	if err := utils.CopyFile(srcPath, jarPath); err != nil {
+		return "", fmt.Errorf("failed to store jar: %w", err)
+	}
+
+	meta := &CacheMetadata{
+		Version:     version,
+		Build:       build,
+		ServerType:  serverType,
+		DownloadURL: sourceURL,
+		Checksum:    checksum,
+		Algorithm:   "sha256",
+		CachedAt:    time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := c.SaveMetadata(serverType, version, build, meta); err != nil {
+		return "", err
+	}
+
+	return jarPath, nil
+}
+
+// RemoveJar removes a cached jar and its metadata
+func (c *Cache) RemoveJar(serverType, version, build string) error {
+	for _, path := range []string{c.GetJarPath(serverType, version, build), c.GetMetadataPath(serverType, version, build)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove cached jar: %w", err)
+		}
+	}
+	return nil
+}
+
+// FileChecksum returns the hex encoded checksum of a file
+func FileChecksum(path, algorithm string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
 	}
 	defer func() { _ = file.Close() }()
 
-	// For now, only implement SHA256 verification
-	// SHA1 can be added later if needed
-	if algorithm != "sha256" && algorithm != "sha1" {
-		return false, fmt.Errorf("unsupported checksum algorithm: %s", algorithm)
+	hasher, err := newHasher(algorithm)
+	if err != nil {
+		return "", err
 	}
 
-	hasher := sha256.New()
 	if _, err := io.Copy(hasher, file); err != nil {
-		return false, fmt.Errorf("failed to compute checksum: %w", err)
+		return "", fmt.Errorf("failed to compute checksum: %w", err)
 	}
 
-	actualChecksum := hex.EncodeToString(hasher.Sum(nil))
-	return actualChecksum == expectedChecksum, nil
+	return hex.EncodeToString(hasher.Sum(nil)), nil
 }
 
 // EnsureJarsDir ensures the jars directory exists
@@ -142,3 +142,36 @@ func TestEnsureJarsDir(t *testing.T) {
 	}
 }
 
+func TestVerifyChecksum(t *testing.T) {
+	cache := &Cache{baseDir: t.TempDir()}
+	jarPath := filepath.Join(cache.baseDir, "server.jar")
+	if err := os.WriteFile(jarPath, []byte("server jar"), 0644); err != nil {
+		t.Fatalf("Failed to write jar: %v", err)
+	}
+
+	tests := []struct {
+		algorithm string
+		checksum  string
+		want      bool
+		wantErr   bool
+	}{
+		{"sha256", "68e45cad5e4df11db590dfc8655e7fa2e53212ee0cb0d85d70512c497b704999", true, false},
+		{"sha1", "30b938b11bb1cf842c4c53014e9849e50f597dba", true, false},
+		{"md5", "0f9c9f479c49f445ed7080f74ac79357", true, false},
+		{"md5", "0F9C9F479C49F445ED7080F74AC79357", true, false},
+		{"sha1", "68e45cad5e4df11db590dfc8655e7fa2e53212ee0cb0d85d70512c497b704999", false, false},
+		{"crc32", "00000000", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm+"/"+tt.checksum[:8], func(t *testing.T) {
+			got, err := cache.VerifyChecksum(jarPath, tt.checksum, tt.algorithm)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyChecksum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("VerifyChecksum() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"crypto/md5"
 	"crypto/sha1"
 	"crypto/sha256"
 	"encoding/hex"
@@ -10,6 +11,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/jackh54/mcinit/internal/utils"
@@ -31,6 +33,13 @@ func NewDownloader(cache *Cache) *Downloader {
 	}
 }
 
+// WithTransport makes the downloader send its requests through transport,
+// e.g. to route them to mirrors. A nil transport uses the default.
+func (d *Downloader) WithTransport(transport http.RoundTripper) *Downloader {
+	d.client.Transport = transport
+	return d
+}
+
 // DownloadJar downloads a jar file to the cache
 func (d *Downloader) DownloadJar(url, serverType, version, build, expectedChecksum, algorithm string) (string, error) {
 	// Check if already cached and valid
@@ -119,13 +128,9 @@ func (d *Downloader) downloadFile(url, destPath, expectedChecksum, algorithm str
 	var hasher hash.Hash
 
 	if expectedChecksum != "" {
-		switch algorithm {
-		case "sha256":
-			hasher = sha256.New()
-		case "sha1":
-			hasher = sha1.New()
-		default:
-			return fmt.Errorf("unsupported checksum algorithm: %s", algorithm)
+		hasher, err = newHasher(algorithm)
+		if err != nil {
+			return err
 		}
 		writer = io.MultiWriter(out, hasher)
 	}
@@ -141,7 +146,7 @@ func (d *Downloader) downloadFile(url, destPath, expectedChecksum, algorithm str
 	// Verify checksum if provided
 	if expectedChecksum != "" && hasher != nil {
 		actualChecksum := hex.EncodeToString(hasher.Sum(nil))
-		if actualChecksum != expectedChecksum {
+		if !strings.EqualFold(actualChecksum, expectedChecksum) {
 			return fmt.Errorf("checksum mismatch: expected %s, got %s", expectedChecksum, actualChecksum)
 		}
 		fmt.Printf("Checksum verified (%s)\n", algorithm)
@@ -149,3 +154,18 @@ func (d *Downloader) downloadFile(url, destPath, expectedChecksum, algorithm str
 
 	return nil
 }
+
+// newHasher returns a hash for a checksum algorithm: "sha256", "sha1" or
+// "md5". MD5 is only accepted because some build servers publish nothing else.
+func newHasher(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case "sha256":
+		return sha256.New(), nil
+	case "sha1":
+		return sha1.New(), nil
+	case "md5":
+		return md5.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported checksum algorithm: %s", algorithm)
+	}
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/jackh54/mcinit/internal/server"
+	"github.com/spf13/cobra"
+)
+
+// detachSequence detaches from the console when typed on its own line
+const detachSequence = "~."
+
+var consoleCmd = &cobra.Command{
+	Use:   "console",
+	Short: "Attach to the console of a background server",
+	Long: `Attach to the console of a server started with --background.
+Recent output is replayed, new output is streamed live, and typed lines are sent to the server.
+
+Detach with Ctrl+D, Ctrl+C, or by typing ~. on its own line. The server keeps running.`,
+	Example: `  mcinit start --background
+  mcinit console`,
+	RunE: runConsole,
+}
+
+func runConsole(cmd *cobra.Command, args []string) error {
+	// Get server directory
+	serverDir := "."
+
+	// Create server manager
+	mgr, err := server.NewManager(serverDir)
+	if err != nil {
+		return fmt.Errorf("failed to create server manager: %w", err)
+	}
+
+	client, err := mgr.AttachConsole(server.ConsoleModeAttach)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = client.Close() }()
+
+	printf("Attached to server console (detach with Ctrl+D or %s)\n", detachSequence)
+
+	// Stream console output
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			line, err := client.ReadLine()
+			if err != nil {
+				return
+			}
+			fmt.Println(line)
+		}
+	}()
+
+	// Forward typed lines until the user detaches
+	detached := make(chan struct{})
+	go func() {
+		defer close(detached)
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.TrimSpace(line) == detachSequence {
+				return
+			}
+			if err := client.Send(line); err != nil {
+				return
+			}
+		}
+	}()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	select {
+	case <-closed:
+		printf("\nServer console closed\n")
+	case <-detached:
+		printf("Detached from server console\n")
+	case <-sigChan:
+		printf("\nDetached from server console\n")
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jackh54/mcinit/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	execJSON    bool
+	execTimeout time.Duration
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec <command>",
+	Short: "Run a server command and print its response",
+	Long: `Send a single command to the running server and print the response.
+The command is sent over RCON when enabled, otherwise through the console of a
+server started with --background. Exits non-zero if the command could not be delivered.
+
+Only RCON returns the command's exact reply. The console fallback is best
+effort: it collects console output until the server is quiet for half a
+second, so unrelated log lines printed meanwhile may be included. The --json
+output reports this as "source": "console" instead of "rcon".`,
+	Example: `  mcinit exec op dev
+  mcinit exec save-all
+  mcinit exec --json -- reload confirm`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runExec,
+}
+
+func init() {
+	execCmd.Flags().BoolVar(&execJSON, "json", false, "Print the result as JSON")
+	execCmd.Flags().DurationVar(&execTimeout, "timeout", 10*time.Second, "Time to wait for a response")
+}
+
+// execOutput is the JSON form of an exec result
+type execOutput struct {
+	OK bool `json:"ok"`
+	*server.ExecResult
+	Error string `json:"error,omitempty"`
+}
+
+func runExec(cmd *cobra.Command, args []string) error {
+	// Get server directory
+	serverDir := "."
+	command := strings.Join(args, " ")
+
+	// Create server manager
+	mgr, err := server.NewManager(serverDir)
+	if err != nil {
+		return fmt.Errorf("failed to create server manager: %w", err)
+	}
+
+	result, err := mgr.Exec(command, execTimeout)
+
+	if execJSON {
+		out := execOutput{OK: err == nil, ExecResult: result}
+		if err != nil {
+			out.ExecResult = &server.ExecResult{Command: command}
+			out.Error = err.Error()
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if jsonErr := encoder.Encode(out); jsonErr != nil {
+			return fmt.Errorf("failed to encode result: %w", jsonErr)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to run command: %w", err)
+	}
+
+	if !execJSON && result.Response != "" {
+		fmt.Println(result.Response)
+	}
+
+	return nil
+}
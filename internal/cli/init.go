@@ -1,10 +1,13 @@
 package cli
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/jackh54/mcinit/internal/cache"
@@ -18,19 +21,29 @@ import (
 )
 
 var (
-	serverType  string
-	mcVersion   string
-	serverPath  string
-	serverName  string
-	acceptEula  bool
-	ram         string
-	xms         string
-	xmx         string
-	jvmFlags    string
-	port        int
-	nogui       bool
-	gitignore   bool
-	javaVersion string
+	serverType       string
+	mcVersion        string
+	serverPath       string
+	serverName       string
+	acceptEula       bool
+	ram              string
+	xms              string
+	xmx              string
+	jvmFlags         string
+	port             int
+	nogui            bool
+	gitignore        bool
+	javaVersion      string
+	rconPort         int
+	noRCON           bool
+	loaderVersion    string
+	installerVersion string
+	serverBuild      string
+	apiVersion       string
+	jarSource        string
+	jarChecksum      string
+	linkJar          bool
+	buildChannel     string
 )
 
 var initCmd = &cobra.Command{
@@ -39,15 +52,20 @@ var initCmd = &cobra.Command{
 	Long: `Initialize a new Minecraft server with the specified configuration.
 Downloads the server jar, generates startup scripts, and creates all necessary files.`,
 	Example: `  mcinit init --type vanilla --mc 1.21.4 --accept-eula --ram 4G
+  mcinit init --type vanilla --mc latest-snapshot --accept-eula
   mcinit init --type paper --mc 1.21.4 --accept-eula --ram 4G
   mcinit init --type paper --mc 1.21.4 --path ./test-server --xms 2G --xmx 6G --flags minimal
-  mcinit init --type purpur --mc 1.21.4 --java 21 --port 25566 --nogui`,
+  mcinit init --type paper --mc 1.21.5 --channel experimental
+  mcinit init --type purpur --mc 1.21.4 --java 21 --port 25566 --nogui
+  mcinit init --type fabric --mc 1.21.4 --loader 0.16.10 --accept-eula
+  mcinit init --type sponge --mc 1.21.4 --api 13 --build recommended --accept-eula
+  mcinit init --type custom --mc 1.21.4 --jar ./build/libs/server.jar --link`,
 	RunE: runInit,
 }
 
 func init() {
-	initCmd.Flags().StringVar(&serverType, "type", "paper", "Server type (vanilla|paper|folia|purpur|velocity|waterfall|bungee)")
-	initCmd.Flags().StringVar(&mcVersion, "mc", "", "Minecraft version (required)")
+	initCmd.Flags().StringVar(&serverType, "type", "paper", "Server type (vanilla|paper|folia|purpur|velocity|waterfall|bungee|fabric|quilt|forge|neoforge|spigot|craftbukkit|sponge|spongeforge|custom, or a provider manifest or plugin name)")
+	initCmd.Flags().StringVar(&mcVersion, "mc", "", "Minecraft version, or latest-release or latest-snapshot for vanilla (required)")
 	initCmd.Flags().StringVar(&serverPath, "path", "./server", "Target directory for server")
 	initCmd.Flags().StringVar(&serverName, "name", "", "Server name (default: derived from path)")
 	initCmd.Flags().BoolVar(&acceptEula, "accept-eula", false, "Accept Minecraft EULA")
@@ -59,18 +77,89 @@ func init() {
 	initCmd.Flags().BoolVar(&nogui, "nogui", false, "Disable server GUI")
 	initCmd.Flags().BoolVar(&gitignore, "gitignore", false, "Add server path to .gitignore")
 	initCmd.Flags().StringVar(&javaVersion, "java", "auto", "Java version or path (auto|17|21|/path/to/java)")
+	initCmd.Flags().IntVar(&rconPort, "rcon-port", 25575, "RCON port used for graceful shutdown")
+	initCmd.Flags().BoolVar(&noRCON, "no-rcon", false, "Do not enable RCON in server.properties")
+	initCmd.Flags().StringVar(&loaderVersion, "loader", "latest", "Mod loader version (fabric|quilt)")
+	initCmd.Flags().StringVar(&installerVersion, "installer", "latest", "Mod loader installer version (fabric|quilt)")
+	initCmd.Flags().StringVar(&serverBuild, "build", "latest", "Server build (latest, recommended for sponge, or a build)")
+	initCmd.Flags().StringVar(&buildChannel, "channel", "", "Newest build channel allowed for latest builds (default|experimental) (paper|folia|velocity|waterfall)")
+	initCmd.Flags().StringVar(&apiVersion, "api", "", "Plugin API version to select builds for (sponge|spongeforge)")
+	initCmd.Flags().StringVar(&jarSource, "jar", "", "Server jar path or URL (custom)")
+	initCmd.Flags().StringVar(&jarChecksum, "checksum", "", "Expected checksum of --jar, e.g. sha256:<hex> (custom)")
+	initCmd.Flags().BoolVar(&linkJar, "link", false, "Link the local --jar instead of copying it (custom)")
 
 	_ = initCmd.MarkFlagRequired("mc")
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
+	loadProviderConfig()
 
 	// Validate server type
 	if !provider.Exists(serverType) {
 		return fmt.Errorf("invalid server type: %s (available: %v)", serverType, provider.List())
 	}
 
+	prov, err := provider.Get(serverType)
+	if err != nil {
+		return fmt.Errorf("failed to get provider: %w", err)
+	}
+
+	if custom, ok := prov.(*provider.CustomProvider); ok {
+		if prov, err = custom.WithSource(jarSource, jarChecksum, linkJar); err != nil {
+			return fmt.Errorf("invalid custom jar: %w", err)
+		}
+	} else if cmd.Flags().Changed("jar") || cmd.Flags().Changed("checksum") || cmd.Flags().Changed("link") {
+		return fmt.Errorf("--jar, --checksum and --link are only supported for custom servers")
+	}
+
+	loaderProv, isLoader := prov.(provider.LoaderProvider)
+	if !isLoader && (cmd.Flags().Changed("loader") || cmd.Flags().Changed("installer")) {
+		return fmt.Errorf("--loader and --installer are only supported for mod loaders")
+	}
+	if isLoader && cmd.Flags().Changed("build") {
+		return fmt.Errorf("--build is not supported for mod loaders (use --loader and --installer)")
+	}
+
+	resolver, isResolver := prov.(provider.BuildResolver)
+	if !isResolver && cmd.Flags().Changed("api") {
+		return fmt.Errorf("--api is not supported for %s", serverType)
+	}
+
+	channelResolver, hasChannels := prov.(provider.BuildChannelResolver)
+	if hasChannels {
+		channels := channelResolver.BuildChannels()
+		if buildChannel == "" {
+			buildChannel = channels[0]
+		}
+		valid := false
+		for _, c := range channels {
+			if c == buildChannel {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid build channel: %s (available: %s)", buildChannel, strings.Join(channels, ", "))
+		}
+	} else if cmd.Flags().Changed("channel") {
+		return fmt.Errorf("--channel is not supported for %s", serverType)
+	}
+
+	// Resolve version aliases such as latest-release
+	if versionResolver, ok := prov.(provider.VersionResolver); ok {
+		resolved, err := versionResolver.ResolveVersion(ctx, mcVersion)
+		if err != nil {
+			return fmt.Errorf("failed to resolve version %s: %w", mcVersion, err)
+		}
+		if resolved != mcVersion {
+			printf("Resolved %s to Minecraft %s\n", mcVersion, resolved)
+			mcVersion = resolved
+		}
+	} else if strings.HasPrefix(mcVersion, "latest-") {
+		return fmt.Errorf("--mc %s is not supported for %s", mcVersion, serverType)
+	}
+
 	// Handle RAM flags
 	if ram != "" {
 		if xms != "" || xmx != "" {
@@ -105,11 +194,25 @@ func runInit(cmd *cobra.Command, args []string) error {
 		printf("[DRY RUN] Would create server with:\n")
 		printf("  Type: %s\n", serverType)
 		printf("  Version: %s\n", mcVersion)
+		if isLoader {
+			printf("  Loader: %s (installer %s)\n", loaderVersion, installerVersion)
+		}
+		if jarSource != "" {
+			printf("  Jar: %s\n", jarSource)
+		}
+		if hasChannels {
+			printf("  Channel: %s\n", buildChannel)
+		}
 		printf("  Path: %s\n", absPath)
 		printf("  Name: %s\n", serverName)
 		printf("  RAM: Xms=%s Xmx=%s\n", xms, xmx)
 		printf("  Flags: %s\n", jvmFlags)
 		printf("  Port: %d\n", port)
+		if noRCON {
+			printf("  RCON: disabled\n")
+		} else {
+			printf("  RCON: port %d\n", rconPort)
+		}
 		printf("  EULA: %v\n", acceptEula)
 		return nil
 	}
@@ -158,46 +261,87 @@ func runInit(cmd *cobra.Command, args []string) error {
 		javaInst = inst
 	}
 
-	// Validate Java for Minecraft version
-	if err := javaValidator.ValidateForMinecraft(javaInst, mcVersion); err != nil {
+	// Validate Java for Minecraft version, preferring the provider's metadata
+	// over the built-in version table
+	requiredJava := javaValidator.GetRequiredJavaVersion(mcVersion)
+	if requirer, ok := prov.(provider.JavaRequirer); ok {
+		if required, err := requirer.RequiredJava(ctx, mcVersion); err == nil && required > 0 {
+			requiredJava = required
+		}
+	}
+	if err := javaValidator.ValidateVersion(javaInst, requiredJava); err != nil {
 		errorLog("Java validation warning: %v\n", err)
 		errorLog("Server may not start correctly\n")
 	}
 
 	printf("Found Java %s at %s\n", javaInst.Version, javaInst.Path)
 
-	// Get provider and download jar
-	printf("Downloading %s server jar for Minecraft %s...\n", serverType, mcVersion)
-	prov, err := provider.Get(serverType)
-	if err != nil {
-		return fmt.Errorf("failed to get provider: %w", err)
+	// Mod loaders pin the loader and installer in the build
+	build := serverBuild
+	jarName := "server.jar"
+	var resolvedLoader, resolvedInstaller string
+	if isLoader {
+		resolvedLoader, resolvedInstaller, err = loaderProv.ResolveLoader(ctx, mcVersion, loaderVersion, installerVersion)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s loader: %w", serverType, err)
+		}
+		build = loaderProv.LoaderBuild(resolvedLoader, resolvedInstaller)
+		jarName = loaderProv.ServerJarName()
+		printf("Using %s loader %s (installer %s)\n", serverType, resolvedLoader, resolvedInstaller)
+	} else if isResolver {
+		build, err = resolver.ResolveBuild(ctx, mcVersion, apiVersion, build)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s build: %w", serverType, err)
+		}
+		printf("Using %s build %s\n", serverType, build)
+	} else if hasChannels {
+		var channel string
+		build, channel, err = channelResolver.ResolveChannelBuild(ctx, mcVersion, buildChannel, build)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s build: %w", serverType, err)
+		}
+		printf("Using %s build %s (%s channel)\n", serverType, build, channel)
+		if channel != channelResolver.BuildChannels()[0] {
+			warnLog("%s build %s is from the %s channel and may be unstable, back up your worlds\n", serverType, build, channel)
+		}
+	} else if build == "latest" {
+		// Pin the installed build so the setup can be reproduced and updated
+		latest, err := prov.GetLatestBuild(ctx, mcVersion)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s build: %w", serverType, err)
+		}
+		// Vanilla and custom jars have no builds
+		if latest != "" {
+			build = latest
+			printf("Using %s %s\n", serverType, build)
+		}
 	}
 
-	localPath, downloadURL, checksum, err := prov.DownloadJar(ctx, mcVersion, "latest")
+	// Download or install the server
+	installed, err := installServer(ctx, prov, absPath, javaInst.Path, mcVersion, build, jarName)
 	if err != nil {
-		return fmt.Errorf("failed to download server jar: %w", err)
-	}
-
-	// Copy jar to server directory
-	destJarPath := filepath.Join(absPath, "server.jar")
-	if err := copyFile(localPath, destJarPath); err != nil {
-		return fmt.Errorf("failed to copy server jar: %w", err)
+		return err
 	}
 
-	printf("Server jar downloaded successfully\n")
-
 	// Create configuration
 	cfg := config.DefaultConfig()
 	cfg.Server.Type = serverType
 	cfg.Server.MinecraftVersion = mcVersion
-	cfg.Server.JarPath = "server.jar"
 	cfg.Server.Name = serverName
-	cfg.Server.DownloadURL = downloadURL
-	algorithm, _, _ := prov.GetChecksum(ctx, mcVersion, "latest")
-	if algorithm == "sha256" {
-		cfg.Server.SHA256 = checksum
-	} else {
-		cfg.Server.SHA1 = checksum
+	recordInstall(&cfg.Server, installed)
+	if build != "latest" {
+		cfg.Server.Build = build
+	}
+	if isLoader {
+		cfg.Server.LoaderVersion = resolvedLoader
+		cfg.Server.InstallerVersion = resolvedInstaller
+	}
+	cfg.Server.APIVersion = apiVersion
+	if hasChannels {
+		cfg.Server.BuildChannel = buildChannel
+	}
+	if jarSource != "" {
+		cfg.Server.Source = customJarSource(absPath, jarSource)
 	}
 
 	cfg.Java.Version = javaVersion
@@ -211,6 +355,16 @@ func runInit(cmd *cobra.Command, args []string) error {
 	cfg.ServerConfig.Port = port
 	cfg.ServerConfig.NoGUI = nogui
 
+	if !noRCON {
+		password, err := generatePassword()
+		if err != nil {
+			return fmt.Errorf("failed to generate RCON password: %w", err)
+		}
+		cfg.RCON.Enabled = true
+		cfg.RCON.Port = rconPort
+		cfg.RCON.Password = password
+	}
+
 	cfg.EULA.Accepted = acceptEula
 	if acceptEula {
 		cfg.EULA.AcceptedAt = time.Now().UTC()
@@ -238,6 +392,11 @@ func runInit(cmd *cobra.Command, args []string) error {
 	// Generate server.properties
 	serverPropsPath := filepath.Join(absPath, "server.properties")
 	serverPropsContent := fmt.Sprintf("server-port=%d\n", port)
+	if cfg.RCON.Enabled {
+		// RCON lets mcinit stop the server cleanly
+		serverPropsContent += fmt.Sprintf("enable-rcon=true\nrcon.port=%d\nrcon.password=%s\nbroadcast-rcon-to-ops=false\n",
+			cfg.RCON.Port, cfg.RCON.Password)
+	}
 	if err := os.WriteFile(serverPropsPath, []byte(serverPropsContent), 0644); err != nil {
 		return fmt.Errorf("failed to create server.properties: %w", err)
 	}
@@ -251,7 +410,11 @@ func runInit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to build JVM flags: %w", err)
 	}
 
-	if err := scriptGen.GenerateAll(javaInst.Path, "server.jar", xms, xmx, jvmFlagsArr); err != nil {
+	if cfg.Server.ArgsFile != "" {
+		scriptGen.SetArgsFiles(cfg.Server.ArgsFile, cfg.Server.PlatformArgsFile("windows"))
+	}
+
+	if err := scriptGen.GenerateAll(javaInst.Path, cfg.Server.JarPath, xms, xmx, jvmFlagsArr); err != nil {
 		return fmt.Errorf("failed to generate scripts: %w", err)
 	}
 
@@ -296,19 +459,81 @@ func runInit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func copyFile(src, dst string) error {
-	sourceFile, err := os.Open(src)
+// installServer puts the server jar into serverDir, either by running the
+// provider's installer or by downloading the jar and copying it as jarName
+func installServer(ctx context.Context, prov provider.Provider, serverDir, javaPath, version, build, jarName string) (*provider.InstallResult, error) {
+	if installer, ok := prov.(provider.Installer); ok {
+		printf("Installing %s server for Minecraft %s...\n", prov.GetName(), version)
+		result, err := installer.Install(ctx, serverDir, javaPath, version, build)
+		if err != nil {
+			return nil, fmt.Errorf("failed to install server: %w", err)
+		}
+		printf("Server installed successfully\n")
+		return result, nil
+	}
+
+	printf("Downloading %s server jar for Minecraft %s...\n", prov.GetName(), version)
+	localPath, downloadURL, checksum, err := prov.DownloadJar(ctx, version, build)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to download server jar: %w", err)
+	}
+
+	// Copy jar to server directory
+	if err := utils.CopyFile(localPath, filepath.Join(serverDir, jarName)); err != nil {
+		return nil, fmt.Errorf("failed to copy server jar: %w", err)
+	}
+
+	printf("Server jar downloaded successfully\n")
+
+	algorithm, _, _ := prov.GetChecksum(ctx, version, build)
+	return &provider.InstallResult{
+		JarPath:     jarName,
+		DownloadURL: downloadURL,
+		Checksum:    checksum,
+		Algorithm:   algorithm,
+	}, nil
+}
+
+// recordInstall records where an installed server jar came from
+func recordInstall(server *config.ServerConfig, installed *provider.InstallResult) {
+	server.JarPath = installed.JarPath
+	server.ArgsFile = installed.ArgsFile
+	server.DownloadURL = installed.DownloadURL
+
+	// Only the checksum of the installed jar is kept
+	server.SHA256, server.SHA1, server.MD5 = "", "", ""
+	switch installed.Algorithm {
+	case "sha256":
+		server.SHA256 = installed.Checksum
+	case "md5":
+		server.MD5 = installed.Checksum
+	default:
+		server.SHA1 = installed.Checksum
+	}
+}
+
+// customJarSource returns how a custom jar source is recorded in the config:
+// URLs as given and local paths relative to the server directory
+func customJarSource(serverDir, source string) string {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return source
 	}
-	defer func() { _ = sourceFile.Close() }()
 
-	destFile, err := os.Create(dst)
+	absSource, err := filepath.Abs(source)
 	if err != nil {
-		return err
+		return source
+	}
+	if rel, err := utils.RelativePath(serverDir, absSource); err == nil {
+		return filepath.ToSlash(rel)
 	}
-	defer func() { _ = destFile.Close() }()
+	return absSource
+}
 
-	_, err = io.Copy(destFile, sourceFile)
-	return err
+// generatePassword generates a random RCON password
+func generatePassword() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
 }
@@ -3,7 +3,9 @@ package cli
 import (
 	"fmt"
 	"os"
+	"sync"
 
+	"github.com/jackh54/mcinit/internal/provider"
 	"github.com/spf13/cobra"
 )
 
@@ -39,6 +41,38 @@ func init() {
 	rootCmd.AddCommand(restartCmd)
 	rootCmd.AddCommand(logsCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(consoleCmd)
+	rootCmd.AddCommand(execCmd)
+	rootCmd.AddCommand(superviseCmd)
+	rootCmd.AddCommand(versionsCmd)
+	rootCmd.AddCommand(updateCmd)
+}
+
+// providerConfigOnce makes loadProviderConfig run at most once
+var providerConfigOnce sync.Once
+
+// loadProviderConfig applies the user's mirror configuration and registers
+// the providers declared by manifests in the user's providers directory and
+// the provider plugins on PATH. Only commands that resolve a server type
+// call it, so the others do not scan PATH.
+func loadProviderConfig() {
+	providerConfigOnce.Do(func() {
+		if path, err := provider.MirrorsFile(); err == nil {
+			if err := provider.LoadMirrors(path); err != nil {
+				errorLog("%v\n", err)
+			}
+		}
+
+		if dir, err := provider.ProvidersDir(); err == nil {
+			if _, err := provider.LoadManifests(dir); err != nil {
+				errorLog("%v\n", err)
+			}
+		}
+
+		if _, err := provider.LoadPlugins(); err != nil {
+			errorLog("%v\n", err)
+		}
+	})
 }
 
 // printf prints formatted output if not in dry-run mode
@@ -48,6 +82,11 @@ func printf(format string, args ...interface{}) {
 	}
 }
 
+// warnLog prints warnings
+func warnLog(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "[WARN] "+format, args...)
+}
+
 // errorLog prints error messages
 func errorLog(format string, args ...interface{}) {
 	fmt.Fprintf(os.Stderr, "[ERROR] "+format, args...)
@@ -2,14 +2,17 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/jackh54/mcinit/internal/server"
 	"github.com/spf13/cobra"
 )
 
 var (
-	background bool
-	extraArgs  string
+	background   bool
+	extraArgs    string
+	waitReady    bool
+	readyTimeout time.Duration
 )
 
 var startCmd = &cobra.Command{
@@ -18,6 +21,7 @@ var startCmd = &cobra.Command{
 	Long:  `Start the Minecraft server in the current directory.`,
 	Example: `  mcinit start
   mcinit start --background
+  mcinit start --background --wait-ready --timeout 5m
   mcinit start --args "-XX:+UseG1GC"`,
 	RunE: runStart,
 }
@@ -25,9 +29,15 @@ var startCmd = &cobra.Command{
 func init() {
 	startCmd.Flags().BoolVar(&background, "background", false, "Run server in background")
 	startCmd.Flags().StringVar(&extraArgs, "args", "", "Additional JVM arguments")
+	startCmd.Flags().BoolVar(&waitReady, "wait-ready", false, "Wait until the server is accepting players (requires --background)")
+	startCmd.Flags().DurationVar(&readyTimeout, "timeout", 5*time.Minute, "Maximum time to wait with --wait-ready")
 }
 
 func runStart(cmd *cobra.Command, args []string) error {
+	if waitReady && !background {
+		return fmt.Errorf("--wait-ready requires --background")
+	}
+
 	// Get server directory
 	serverDir := "."
 
@@ -55,6 +65,16 @@ func runStart(cmd *cobra.Command, args []string) error {
 
 	if background {
 		printf("Server started in background\n")
+		printf("Console output: %s\n", server.ConsoleLogPath(serverDir))
+
+		if waitReady {
+			printf("Waiting for server to become ready...\n")
+			state, err := mgr.WaitReady(readyTimeout)
+			if err != nil {
+				return err
+			}
+			printf("Server ready (started in %s)\n", state.FormatStartup())
+		}
 	} else {
 		printf("Server stopped\n")
 	}
@@ -1,18 +1,38 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/jackh54/mcinit/internal/server"
 	"github.com/spf13/cobra"
 )
 
+// statusPingTimeout bounds how long status waits for the server to answer a ping
+const statusPingTimeout = 3 * time.Second
+
 var statusCmd = &cobra.Command{
-	Use:     "status",
-	Short:   "Show server status (running/stopped, PID, uptime)",
-	Long:    `Display the current status of the Minecraft server.`,
-	Example: `  mcinit status`,
-	RunE:    runStatus,
+	Use:   "status",
+	Short: "Show server status (running/stopped, PID, uptime)",
+	Long:  `Display the current status of the Minecraft server.`,
+	Example: `  mcinit status
+  mcinit status --watch --interval 5s`,
+	RunE: runStatus,
+}
+
+var (
+	statusWatch    bool
+	statusInterval time.Duration
+)
+
+func init() {
+	statusCmd.Flags().BoolVar(&statusWatch, "watch", false, "Refresh the status until interrupted")
+	statusCmd.Flags().DurationVar(&statusInterval, "interval", 2*time.Second, "Refresh interval with --watch")
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
@@ -25,9 +45,53 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create server manager: %w", err)
 	}
 
+	// The config is needed for the ping port and the heap size
+	_ = mgr.LoadConfig()
+
+	view := &statusView{mgr: mgr}
+	if !statusWatch {
+		return view.print()
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	for {
+		// Clear the terminal before redrawing
+		printf("\033[H\033[2J")
+		printf("Every %s: mcinit status\t%s\n\n", statusInterval, time.Now().Format("2006-01-02 15:04:05"))
+		if err := view.print(); err != nil {
+			return err
+		}
+
+		select {
+		case <-sigChan:
+			return nil
+		case <-time.After(statusInterval):
+		}
+	}
+}
+
+// statusView prints the server status, keeping the resource usage sampler
+// between refreshes so CPU usage covers the whole interval
+type statusView struct {
+	mgr        *server.Manager
+	sampler    *server.UsageSampler
+	samplerPID int
+}
+
+// print prints the current server status
+func (v *statusView) print() error {
+	mgr := v.mgr
+
 	// Check status
+	if mgr.IsRestarting() {
+		printRestartingStatus(mgr)
+		return nil
+	}
 	if !mgr.IsRunning() {
-		printf("Server status: STOPPED\n")
+		printStoppedStatus(mgr)
 		return nil
 	}
 
@@ -41,6 +105,130 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	printf("PID: %d\n", state.PID)
 	printf("Uptime: %s\n", state.FormatUptime())
 	printf("Started: %s\n", state.StartTime.Format("2006-01-02 15:04:05"))
+	if state.Ready {
+		printf("Ready: yes (startup took %s)\n", state.FormatStartup())
+	} else {
+		printf("Ready: no (still starting)\n")
+	}
+	if state.Restarts > 0 {
+		printf("Restarts: %d (last exit: %s, %s)\n", state.Restarts, state.LastExit.Summary(), state.LastExit.FormatAgo())
+	}
+
+	v.printUsage(state)
+	printPingStatus(mgr, state)
 
 	return nil
 }
+
+// printUsage prints the resource usage of the server process group
+func (v *statusView) printUsage(state *server.State) {
+	// A restarted server is a new process group
+	if v.sampler == nil || v.samplerPID != state.PID {
+		sampler, err := v.mgr.UsageSampler()
+		if err != nil {
+			return
+		}
+		v.sampler, v.samplerPID = sampler, state.PID
+	}
+
+	usage, err := v.sampler.Sample()
+	if errors.Is(err, server.ErrUsageUnsupported) {
+		return
+	}
+	if err != nil {
+		printf("Resources: unavailable (%v)\n", err)
+		return
+	}
+
+	printf("CPU: %.1f%%\n", usage.CPUPercent)
+	if usage.MaxHeap > 0 {
+		printf("Memory: %s (%.0f%% of %s Xmx)\n", formatBytes(usage.RSS),
+			float64(usage.RSS)/float64(usage.MaxHeap)*100, formatBytes(usage.MaxHeap))
+	} else {
+		printf("Memory: %s\n", formatBytes(usage.RSS))
+	}
+	printf("Threads: %d\n", usage.Threads)
+	printf("Open files: %d\n", usage.OpenFiles)
+	if usage.Processes > 1 {
+		printf("Processes: %d\n", usage.Processes)
+	}
+}
+
+// formatBytes returns a human-readable size using binary units, e.g. "1.5 GiB"
+func formatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// printPingStatus prints what the server reports through Server List Ping
+func printPingStatus(mgr *server.Manager, state *server.State) {
+	result, err := mgr.Ping(statusPingTimeout)
+	if err != nil {
+		if state.Ready {
+			printf("Ping: no response (%v)\n", err)
+		} else {
+			printf("Ping: no response (server is still starting)\n")
+		}
+		return
+	}
+
+	if result.MOTD != "" {
+		printf("MOTD: %s\n", result.MOTD)
+	}
+	printf("Players: %d/%d", result.OnlinePlayers, result.MaxPlayers)
+	if len(result.Players) > 0 {
+		printf(" (%s)", strings.Join(result.Players, ", "))
+	}
+	printf("\n")
+	printf("Version: %s (protocol %d)\n", result.Version, result.Protocol)
+	printf("Latency: %dms\n", result.Latency.Milliseconds())
+}
+
+// printStoppedStatus prints the status of a stopped server, including how
+// its last run ended when known
+func printStoppedStatus(mgr *server.Manager) {
+	state, err := mgr.GetStatus()
+	if err != nil || state.LastExit == nil {
+		printf("Server status: STOPPED\n")
+		return
+	}
+
+	exit := state.LastExit
+	if exit.Crashed {
+		printf("Server status: CRASHED %s (%s)\n", exit.FormatAgo(), exit.Summary())
+	} else {
+		printf("Server status: STOPPED %s (%s)\n", exit.FormatAgo(), exit.Summary())
+	}
+	printf("Last run: %s - %s\n",
+		state.StartTime.Format("2006-01-02 15:04:05"),
+		exit.EndTime.Format("2006-01-02 15:04:05"))
+}
+
+// printRestartingStatus prints the status of a server waiting to be
+// restarted by its supervisor
+func printRestartingStatus(mgr *server.Manager) {
+	state, err := mgr.GetStatus()
+	if err != nil {
+		printf("Server status: RESTARTING\n")
+		return
+	}
+
+	wait := time.Until(state.NextRestart).Round(time.Second)
+	if wait < 0 {
+		wait = 0
+	}
+	printf("Server status: RESTARTING in %s (restart #%d)\n", wait, state.Restarts)
+	if state.LastExit != nil {
+		printf("Last exit: %s, %s\n", state.LastExit.Summary(), state.LastExit.FormatAgo())
+	}
+	printf("Supervisor PID: %d\n", state.SupervisorPID)
+}
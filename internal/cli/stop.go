@@ -34,8 +34,8 @@ func runStop(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create server manager: %w", err)
 	}
 
-	// Check if running
-	if !mgr.IsRunning() {
+	// Check if running, or about to be restarted by its supervisor
+	if !mgr.IsRunning() && !mgr.IsRestarting() {
 		return fmt.Errorf("server is not running")
 	}
 
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/jackh54/mcinit/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var superviseArgs string
+
+// superviseCmd is started by "mcinit start --background" and owns the
+// server process for as long as it runs
+var superviseCmd = &cobra.Command{
+	Use:    server.SupervisorCommand,
+	Short:  "Run the server under a detached supervisor (internal)",
+	Hidden: true,
+	RunE:   runSupervise,
+}
+
+func init() {
+	superviseCmd.Flags().StringVar(&superviseArgs, "args", "", "Additional JVM arguments")
+}
+
+func runSupervise(cmd *cobra.Command, args []string) error {
+	// The supervisor is started in the server directory
+	serverDir := "."
+
+	mgr, err := server.NewManager(serverDir)
+	if err != nil {
+		return fmt.Errorf("failed to create server manager: %w", err)
+	}
+
+	return mgr.Supervise(superviseArgs)
+}
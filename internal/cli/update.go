@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/jackh54/mcinit/internal/config"
+	"github.com/jackh54/mcinit/internal/provider"
+	"github.com/jackh54/mcinit/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update the server jar to the newest build of its Minecraft version",
+	Long: `Update the server jar to the newest build of the Minecraft version in
+mcinit.json. Builds newer than the recorded build channel are skipped, and the
+Minecraft version itself is never changed.
+
+With --dry-run, only print the target build and the changes of the builds
+between the installed and the target build
+(paper|folia|velocity|waterfall|purpur|bungee).`,
+	Example: `  mcinit update --dry-run
+  mcinit update`,
+	Args: cobra.NoArgs,
+	RunE: runUpdate,
+}
+
+func runUpdate(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	loadProviderConfig()
+
+	// Get server directory
+	serverDir := "."
+	cfgPath := filepath.Join(serverDir, "mcinit.json")
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	prov, err := provider.Get(cfg.Server.Type)
+	if err != nil {
+		return fmt.Errorf("failed to get provider: %w", err)
+	}
+
+	version := cfg.Server.MinecraftVersion
+	current := cfg.Server.Build
+	if current == "latest" {
+		// Recorded by older versions of init, the installed build is unknown
+		current = ""
+	}
+	target, err := latestBuild(ctx, prov, cfg.Server)
+	if err != nil {
+		return err
+	}
+
+	if !isNewerBuild(current, target) {
+		fmt.Printf("%s %s is up to date (build %s)\n", cfg.Server.Type, version, current)
+		return nil
+	}
+
+	if current == "" {
+		fmt.Printf("Updating %s %s to build %s, the installed build is unknown\n", cfg.Server.Type, version, target)
+	} else {
+		fmt.Printf("Updating %s %s from build %s to build %s\n", cfg.Server.Type, version, current, target)
+	}
+	if lister, ok := prov.(provider.ChangeLister); ok {
+		if err := printChangesBetween(ctx, lister, version, current, target); err != nil {
+			warnLog("Failed to list the changes: %v\n", err)
+		}
+	}
+
+	// Dry-run mode
+	if dryRun {
+		return nil
+	}
+
+	mgr, err := server.NewManager(serverDir)
+	if err != nil {
+		return fmt.Errorf("failed to create server manager: %w", err)
+	}
+	if mgr.IsRunning() {
+		return fmt.Errorf("server is running, stop it before updating")
+	}
+
+	javaPath, err := mgr.JavaPath()
+	if err != nil {
+		return fmt.Errorf("failed to resolve Java: %w", err)
+	}
+
+	jarName := cfg.Server.JarPath
+	if jarName == "" {
+		jarName = "server.jar"
+	}
+	installed, err := installServer(ctx, prov, serverDir, javaPath, version, target, jarName)
+	if err != nil {
+		return err
+	}
+
+	cfg.Server.Build = target
+	recordInstall(&cfg.Server, installed)
+	if err := config.Save(cfg, cfgPath); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	printf("Updated %s %s to build %s\n", cfg.Server.Type, version, target)
+	return nil
+}
+
+// latestBuild returns the newest build of the server's Minecraft version,
+// selected the way init selected the installed build
+func latestBuild(ctx context.Context, prov provider.Provider, srv config.ServerConfig) (string, error) {
+	if _, ok := prov.(*provider.CustomProvider); ok {
+		return "", fmt.Errorf("custom servers cannot be updated, replace server.jar with the new jar")
+	}
+	if _, ok := prov.(provider.LoaderProvider); ok {
+		return "", fmt.Errorf("%s servers cannot be updated, run init with the new --loader", srv.Type)
+	}
+
+	var build string
+	var err error
+	switch p := prov.(type) {
+	case provider.BuildChannelResolver:
+		channel := srv.BuildChannel
+		if channel == "" {
+			channel = p.BuildChannels()[0]
+		}
+		build, _, err = p.ResolveChannelBuild(ctx, srv.MinecraftVersion, channel, "latest")
+	case provider.BuildResolver:
+		build, err = p.ResolveBuild(ctx, srv.MinecraftVersion, srv.APIVersion, "latest")
+	default:
+		build, err = prov.GetLatestBuild(ctx, srv.MinecraftVersion)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get latest %s %s build: %w", srv.Type, srv.MinecraftVersion, err)
+	}
+	if build == "" {
+		return "", fmt.Errorf("%s has no builds to update to", srv.Type)
+	}
+	return build, nil
+}
+
+// isNewerBuild reports whether target should replace the current build.
+// Numbered builds are compared as numbers, others only for equality. An
+// unknown current build is always replaced.
+func isNewerBuild(current, target string) bool {
+	if current == "" {
+		return true
+	}
+	currentNumber, currentErr := strconv.Atoi(current)
+	targetNumber, targetErr := strconv.Atoi(target)
+	if currentErr == nil && targetErr == nil {
+		return targetNumber > currentNumber
+	}
+	return target != current
+}
+
+// printChangesBetween prints the builds after current up to and including
+// target, newest first
+func printChangesBetween(ctx context.Context, lister provider.ChangeLister, version, current, target string) error {
+	builds, err := lister.Changes(ctx, version)
+	if err != nil {
+		return err
+	}
+
+	start := -1
+	for i, build := range builds {
+		if build.Build == target {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return fmt.Errorf("build %s is not listed", target)
+	}
+
+	end := start + 1
+	for end < len(builds) && builds[end].Build != current {
+		end++
+	}
+	if end == len(builds) {
+		// The installed build is unknown or no longer listed
+		if current != "" {
+			warnLog("Build %s is not listed, showing only the changes of build %s\n", current, target)
+		}
+		end = start + 1
+	}
+
+	fmt.Println()
+	printBuilds(builds[start:end])
+	return nil
+}
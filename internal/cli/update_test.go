@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jackh54/mcinit/internal/config"
+	"github.com/jackh54/mcinit/internal/provider"
+)
+
+// runCommand runs mcinit with args and returns what it printed to stdout
+func runCommand(t *testing.T, args ...string) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error = %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- string(data)
+	}()
+
+	rootCmd.SetArgs(args)
+	err = rootCmd.Execute()
+	_ = w.Close()
+	out := <-output
+	if err != nil {
+		t.Fatalf("mcinit %s error = %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return out
+}
+
+func TestUpdateAfterInitIsUpToDate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake java is a shell script")
+	}
+
+	var downloads atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/purpur/1.21.4", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"project":"purpur","version":"1.21.4","builds":{"latest":"2400","all":[
+			{"build":"2399","result":"SUCCESS","commits":[]},
+			{"build":"2400","result":"SUCCESS","commits":[]}]}}`)
+	})
+	mux.HandleFunc("/v2/purpur/1.21.4/2400/download", func(w http.ResponseWriter, r *http.Request) {
+		downloads.Add(1)
+		fmt.Fprint(w, "purpur server jar")
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	t.Setenv("MCINIT_PURPUR_URL", server.URL)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// The registered provider uses the cache directory of the user
+	registered, err := provider.Get("purpur")
+	if err != nil {
+		t.Fatalf("provider.Get() error = %v", err)
+	}
+	provider.Register("purpur", provider.NewPurpurProvider())
+	t.Cleanup(func() { provider.Register("purpur", registered) })
+
+	javaPath := filepath.Join(t.TempDir(), "java")
+	script := "#!/bin/sh\necho 'openjdk version \"21.0.5\" 2024-10-15' >&2\n"
+	if err := os.WriteFile(javaPath, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake java: %v", err)
+	}
+
+	serverDir := filepath.Join(t.TempDir(), "server")
+	runCommand(t, "init", "--type", "purpur", "--mc", "1.21.4", "--path", serverDir, "--java", javaPath, "--accept-eula")
+
+	cfg, err := config.Load(filepath.Join(serverDir, "mcinit.json"))
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	if cfg.Server.Build != "2400" {
+		t.Errorf("init recorded build %q, want 2400", cfg.Server.Build)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("os.Getwd() error = %v", err)
+	}
+	if err := os.Chdir(serverDir); err != nil {
+		t.Fatalf("os.Chdir() error = %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	out := runCommand(t, "update")
+	if !strings.Contains(out, "purpur 1.21.4 is up to date (build 2400)") {
+		t.Errorf("update printed %q, want up to date", out)
+	}
+	if n := downloads.Load(); n != 1 {
+		t.Errorf("jar downloaded %d times, want once by init", n)
+	}
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/jackh54/mcinit/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+var versionsCmd = &cobra.Command{
+	Use:   "versions <type> [mc-version]",
+	Short: "List the Minecraft versions or builds available for a server type",
+	Long: `List the Minecraft versions a server type can be initialized with.
+
+Vanilla publishes snapshots, pre-releases and old versions next to releases,
+select them with --channel. Vanilla also accepts latest-release and
+latest-snapshot as --mc in init.
+
+With a Minecraft version and --builds, list the builds of that version with
+their dates, channels and commits, newest first, to see what upgrading to a
+newer build brings (paper|folia|velocity|waterfall|purpur|bungee).`,
+	Example: `  mcinit versions paper
+  mcinit versions vanilla --channel snapshot
+  mcinit versions vanilla --channel all
+  mcinit versions paper 1.21.4 --builds
+  mcinit versions purpur 1.21.4 --builds --limit 0`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runVersions,
+}
+
+var (
+	versionsChannel string
+	versionsBuilds  bool
+	versionsLimit   int
+)
+
+func init() {
+	versionsCmd.Flags().StringVar(&versionsChannel, "channel", "release", "Version channel (release|snapshot|pre-release|old_beta|all), vanilla only")
+	versionsCmd.Flags().BoolVar(&versionsBuilds, "builds", false, "List the builds of the given Minecraft version with their changes")
+	versionsCmd.Flags().IntVar(&versionsLimit, "limit", 10, "Number of builds to list with --builds, 0 for all")
+}
+
+func runVersions(cmd *cobra.Command, args []string) error {
+	serverType := args[0]
+	loadProviderConfig()
+	prov, err := provider.Get(serverType)
+	if err != nil {
+		return fmt.Errorf("invalid server type: %s (available: %v)", serverType, provider.List())
+	}
+
+	if versionsBuilds {
+		if len(args) != 2 {
+			return fmt.Errorf("--builds requires a Minecraft version")
+		}
+		return listBuilds(cmd, prov, serverType, args[1])
+	}
+	if len(args) == 2 {
+		return fmt.Errorf("a Minecraft version is only accepted with --builds")
+	}
+
+	var versions []string
+	if lister, ok := prov.(provider.ChannelLister); ok {
+		versions, err = lister.GetVersionsInChannel(cmd.Context(), versionsChannel)
+	} else if cmd.Flags().Changed("channel") {
+		return fmt.Errorf("--channel is not supported for %s", serverType)
+	} else {
+		versions, err = prov.GetAvailableVersions(cmd.Context())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list %s versions: %w", serverType, err)
+	}
+
+	for _, version := range versions {
+		fmt.Println(version)
+	}
+	return nil
+}
+
+// listBuilds prints the newest builds of a version and their commits
+func listBuilds(cmd *cobra.Command, prov provider.Provider, serverType, version string) error {
+	lister, ok := prov.(provider.ChangeLister)
+	if !ok {
+		return fmt.Errorf("--builds is not supported for %s", serverType)
+	}
+
+	builds, err := lister.Changes(cmd.Context(), version)
+	if err != nil {
+		return fmt.Errorf("failed to list %s %s builds: %w", serverType, version, err)
+	}
+	if versionsLimit > 0 && len(builds) > versionsLimit {
+		builds = builds[:versionsLimit]
+	}
+
+	printBuilds(builds)
+	return nil
+}
+
+// printBuilds prints builds with their changes, in the given order
+func printBuilds(builds []provider.BuildChanges) {
+	for i, build := range builds {
+		if i > 0 {
+			fmt.Println()
+		}
+
+		fmt.Printf("Build %s", build.Build)
+		if !build.Time.IsZero() {
+			fmt.Printf("  %s", build.Time.Format("2006-01-02"))
+		}
+		if build.Channel != "" {
+			fmt.Printf("  [%s]", build.Channel)
+		}
+		if build.Failed {
+			fmt.Print("  (failed)")
+		}
+		fmt.Println()
+
+		if len(build.Commits) == 0 {
+			fmt.Println("  (no changes)")
+		}
+		for _, commit := range build.Commits {
+			fmt.Printf("  %s %s\n", shortHash(commit.Hash), commit.Summary)
+		}
+	}
+}
+
+// shortHash abbreviates a commit hash like git does
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
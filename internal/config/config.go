@@ -1,21 +1,26 @@
 package config
 
 import (
+	"fmt"
+	"path"
 	"time"
 )
 
 // Config represents the mcinit.json configuration
 type Config struct {
-	Version      string        `json:"version"`
-	Server       ServerConfig  `json:"server"`
-	Java         JavaConfig    `json:"java"`
-	JVM          JVMConfig     `json:"jvm"`
-	ServerConfig ServerProps   `json:"serverConfig"`
-	Plugins      PluginsConfig `json:"plugins"`
-	EULA         EULAConfig    `json:"eula"`
-	Paths        PathsConfig   `json:"paths"`
-	CreatedAt    time.Time     `json:"createdAt"`
-	UpdatedAt    time.Time     `json:"updatedAt"`
+	Version      string         `json:"version"`
+	Server       ServerConfig   `json:"server"`
+	Java         JavaConfig     `json:"java"`
+	JVM          JVMConfig      `json:"jvm"`
+	ServerConfig ServerProps    `json:"serverConfig"`
+	RCON         RCONConfig     `json:"rcon"`
+	Shutdown     ShutdownConfig `json:"shutdown"`
+	Restart      RestartConfig  `json:"restart"`
+	Plugins      PluginsConfig  `json:"plugins"`
+	EULA         EULAConfig     `json:"eula"`
+	Paths        PathsConfig    `json:"paths"`
+	CreatedAt    time.Time      `json:"createdAt"`
+	UpdatedAt    time.Time      `json:"updatedAt"`
 }
 
 // ServerConfig represents server-specific configuration
@@ -23,11 +28,28 @@ type ServerConfig struct {
 	Type             string `json:"type"`
 	MinecraftVersion string `json:"minecraftVersion"`
 	Build            string `json:"build,omitempty"`
-	JarPath          string `json:"jarPath"`
+	JarPath          string `json:"jarPath,omitempty"`
+	ArgsFile         string `json:"argsFile,omitempty"` // launched instead of the jar, e.g. Forge's unix_args.txt
 	Name             string `json:"name"`
 	DownloadURL      string `json:"downloadUrl,omitempty"`
 	SHA256           string `json:"sha256,omitempty"`
 	SHA1             string `json:"sha1,omitempty"`
+	MD5              string `json:"md5,omitempty"` // only published by some build servers
+	LoaderVersion    string `json:"loaderVersion,omitempty"`    // mod loaders such as Fabric
+	InstallerVersion string `json:"installerVersion,omitempty"` // mod loaders such as Fabric
+	APIVersion       string `json:"apiVersion,omitempty"`       // plugin API version, e.g. Sponge's
+	Source           string `json:"source,omitempty"`           // custom jar path (relative to the server) or URL, informational only
+	BuildChannel     string `json:"buildChannel,omitempty"`     // build channel preference, e.g. PaperMC's default or experimental
+}
+
+// PlatformArgsFile returns the argument file to launch on goos. Installers
+// write unix_args.txt and win_args.txt side by side and the config records
+// the former.
+func (s ServerConfig) PlatformArgsFile(goos string) string {
+	if s.ArgsFile == "" || goos != "windows" {
+		return s.ArgsFile
+	}
+	return path.Join(path.Dir(s.ArgsFile), "win_args.txt")
 }
 
 // JavaConfig represents Java installation configuration
@@ -47,11 +69,47 @@ type JVMConfig struct {
 
 // ServerProps represents server.properties configuration
 type ServerProps struct {
-	Port        int    `json:"port"`
-	NoGUI       bool   `json:"nogui"`
-	MaxPlayers  int    `json:"maxPlayers"`
-	OnlineMode  bool   `json:"onlineMode"`
-	Difficulty  string `json:"difficulty"`
+	Port       int    `json:"port"`
+	NoGUI      bool   `json:"nogui"`
+	MaxPlayers int    `json:"maxPlayers"`
+	OnlineMode bool   `json:"onlineMode"`
+	Difficulty string `json:"difficulty"`
+}
+
+// RCONConfig represents RCON configuration used to control the server
+type RCONConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Host     string `json:"host,omitempty"`
+	Port     int    `json:"port"`
+	Password string `json:"password,omitempty"`
+}
+
+// ShutdownConfig represents how long each graceful shutdown stage may take
+// (seconds). A timeout of 0 skips the stage; missing timeouts default to 30.
+type ShutdownConfig struct {
+	RCONTimeout  *int `json:"rconTimeout,omitempty"`  // after sending "stop" via RCON
+	StdinTimeout *int `json:"stdinTimeout,omitempty"` // after sending "stop" via the console
+	TermTimeout  *int `json:"termTimeout,omitempty"`  // after sending SIGTERM, before SIGKILL
+}
+
+// Restart policies
+const (
+	RestartNever     = "never"
+	RestartOnFailure = "on-failure"
+	RestartAlways    = "always"
+)
+
+// MaxRestartsLimit is the largest maxRestarts, as the state file only keeps
+// that many restarts to count them
+const MaxRestartsLimit = 20
+
+// RestartConfig represents the automatic restart policy for servers owned by mcinit
+type RestartConfig struct {
+	Policy      string `json:"policy"`      // "never", "on-failure" or "always"
+	MaxRestarts int    `json:"maxRestarts"` // within window, 0 means unlimited, at most MaxRestartsLimit
+	Window      int    `json:"window"`      // seconds
+	Backoff     int    `json:"backoff"`     // initial delay in seconds, doubled for each restart in the window
+	MaxBackoff  int    `json:"maxBackoff"`  // seconds
 }
 
 // PluginsConfig represents plugin linking configuration
@@ -89,8 +147,8 @@ func (c *Config) Validate() error {
 		return &ValidationError{Field: "server.minecraftVersion", Message: "Minecraft version is required"}
 	}
 
-	if c.Server.JarPath == "" {
-		return &ValidationError{Field: "server.jarPath", Message: "jar path is required"}
+	if c.Server.JarPath == "" && c.Server.ArgsFile == "" {
+		return &ValidationError{Field: "server.jarPath", Message: "jar path or argument file is required"}
 	}
 
 	if c.JVM.Xmx == "" {
@@ -101,6 +159,34 @@ func (c *Config) Validate() error {
 		return &ValidationError{Field: "serverConfig.port", Message: "port must be between 1 and 65535"}
 	}
 
+	if c.RCON.Enabled {
+		if c.RCON.Port < 1 || c.RCON.Port > 65535 {
+			return &ValidationError{Field: "rcon.port", Message: "port must be between 1 and 65535"}
+		}
+		if c.RCON.Password == "" {
+			return &ValidationError{Field: "rcon.password", Message: "password is required when RCON is enabled"}
+		}
+	}
+
+	switch c.Restart.Policy {
+	case RestartNever, RestartOnFailure, RestartAlways:
+	default:
+		return &ValidationError{Field: "restart.policy", Message: "policy must be never, on-failure or always"}
+	}
+
+	if c.Restart.MaxRestarts < 0 || c.Restart.Window < 0 || c.Restart.Backoff < 0 || c.Restart.MaxBackoff < 0 {
+		return &ValidationError{Field: "restart", Message: "limits must not be negative"}
+	}
+	if c.Restart.MaxRestarts > MaxRestartsLimit {
+		return &ValidationError{Field: "restart.maxRestarts", Message: fmt.Sprintf("maxRestarts must be at most %d", MaxRestartsLimit)}
+	}
+
+	for _, timeout := range []*int{c.Shutdown.RCONTimeout, c.Shutdown.StdinTimeout, c.Shutdown.TermTimeout} {
+		if timeout != nil && *timeout < 0 {
+			return &ValidationError{Field: "shutdown", Message: "timeouts must not be negative"}
+		}
+	}
+
 	return nil
 }
 
@@ -113,4 +199,3 @@ type ValidationError struct {
 func (e *ValidationError) Error() string {
 	return e.Field + ": " + e.Message
 }
-
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -55,6 +56,67 @@ func TestConfigValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "rcon enabled without password",
+			cfg: func() *Config {
+				c := DefaultConfig()
+				c.Server.MinecraftVersion = "1.21.4"
+				c.RCON.Enabled = true
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "rcon enabled with password",
+			cfg: func() *Config {
+				c := DefaultConfig()
+				c.Server.MinecraftVersion = "1.21.4"
+				c.RCON.Enabled = true
+				c.RCON.Password = "secret"
+				return c
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "invalid restart policy",
+			cfg: func() *Config {
+				c := DefaultConfig()
+				c.Server.MinecraftVersion = "1.21.4"
+				c.Restart.Policy = "sometimes"
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "more restarts than the state file keeps",
+			cfg: func() *Config {
+				c := DefaultConfig()
+				c.Server.MinecraftVersion = "1.21.4"
+				c.Restart.MaxRestarts = MaxRestartsLimit + 1
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "argument file instead of jar",
+			cfg: func() *Config {
+				c := DefaultConfig()
+				c.Server.MinecraftVersion = "1.21.4"
+				c.Server.JarPath = ""
+				c.Server.ArgsFile = "libraries/unix_args.txt"
+				return c
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "missing jar and argument file",
+			cfg: &Config{
+				Server:       ServerConfig{Type: "paper", MinecraftVersion: "1.21.4"},
+				JVM:          JVMConfig{Xmx: "4G"},
+				ServerConfig: ServerProps{Port: 25565},
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid port",
 			cfg: &Config{
@@ -131,6 +193,33 @@ func TestApplyDefaults(t *testing.T) {
 	if cfg.JVM.Xms == "" {
 		t.Error("Xms should not be empty after ApplyDefaults()")
 	}
+
+	if cfg.RCON.Port != 25575 {
+		t.Errorf("Expected default RCON port 25575, got %d", cfg.RCON.Port)
+	}
+
+	if cfg.Shutdown.TermTimeout == nil || *cfg.Shutdown.TermTimeout == 0 {
+		t.Error("Shutdown timeouts should not be zero after ApplyDefaults()")
+	}
+}
+
+func TestLoadShutdownTimeouts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcinit.json")
+	data := `{"server":{"type":"paper","minecraftVersion":"1.21.4","name":"test"},"shutdown":{"rconTimeout":0,"termTimeout":10}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// An explicit 0 disables the stage, a missing timeout gets the default
+	got := []int{*cfg.Shutdown.RCONTimeout, *cfg.Shutdown.StdinTimeout, *cfg.Shutdown.TermTimeout}
+	if want := []int{0, 30, 10}; !reflect.DeepEqual(got, want) {
+		t.Errorf("shutdown timeouts = %v, want %v", got, want)
+	}
 }
 
 func TestConfigJSON(t *testing.T) {
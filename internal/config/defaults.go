@@ -4,6 +4,14 @@ import (
 	"time"
 )
 
+// defaultShutdownTimeout is the default timeout of each shutdown stage (seconds)
+const defaultShutdownTimeout = 30
+
+// intPtr returns a pointer to v
+func intPtr(v int) *int {
+	return &v
+}
+
 // DefaultConfig returns a new Config with default values
 func DefaultConfig() *Config {
 	now := time.Now().UTC()
@@ -28,11 +36,28 @@ func DefaultConfig() *Config {
 			CustomFlags: []string{},
 		},
 		ServerConfig: ServerProps{
-			Port:        25565,
-			NoGUI:       true,
-			MaxPlayers:  20,
-			OnlineMode:  false,
-			Difficulty:  "easy",
+			Port:       25565,
+			NoGUI:      true,
+			MaxPlayers: 20,
+			OnlineMode: false,
+			Difficulty: "easy",
+		},
+		RCON: RCONConfig{
+			Enabled: false,
+			Host:    "127.0.0.1",
+			Port:    25575,
+		},
+		Shutdown: ShutdownConfig{
+			RCONTimeout:  intPtr(defaultShutdownTimeout),
+			StdinTimeout: intPtr(defaultShutdownTimeout),
+			TermTimeout:  intPtr(defaultShutdownTimeout),
+		},
+		Restart: RestartConfig{
+			Policy:      RestartNever,
+			MaxRestarts: 5,
+			Window:      300,
+			Backoff:     5,
+			MaxBackoff:  300,
 		},
 		Plugins: PluginsConfig{
 			Links: []PluginLink{},
@@ -63,7 +88,7 @@ func (c *Config) ApplyDefaults() {
 		c.Server.Build = "latest"
 	}
 
-	if c.Server.JarPath == "" {
+	if c.Server.JarPath == "" && c.Server.ArgsFile == "" {
 		c.Server.JarPath = "server.jar"
 	}
 
@@ -107,6 +132,43 @@ func (c *Config) ApplyDefaults() {
 		c.ServerConfig.Difficulty = "easy"
 	}
 
+	if c.RCON.Host == "" {
+		c.RCON.Host = "127.0.0.1"
+	}
+
+	if c.RCON.Port == 0 {
+		c.RCON.Port = 25575
+	}
+
+	// 0 disables a shutdown stage, only missing timeouts get the default
+	if c.Shutdown.RCONTimeout == nil {
+		c.Shutdown.RCONTimeout = intPtr(defaultShutdownTimeout)
+	}
+
+	if c.Shutdown.StdinTimeout == nil {
+		c.Shutdown.StdinTimeout = intPtr(defaultShutdownTimeout)
+	}
+
+	if c.Shutdown.TermTimeout == nil {
+		c.Shutdown.TermTimeout = intPtr(defaultShutdownTimeout)
+	}
+
+	if c.Restart.Policy == "" {
+		c.Restart.Policy = RestartNever
+	}
+
+	if c.Restart.Window == 0 {
+		c.Restart.Window = 300
+	}
+
+	if c.Restart.Backoff == 0 {
+		c.Restart.Backoff = 5
+	}
+
+	if c.Restart.MaxBackoff == 0 {
+		c.Restart.MaxBackoff = 300
+	}
+
 	if c.Plugins.Links == nil {
 		c.Plugins.Links = []PluginLink{}
 	}
@@ -123,4 +185,3 @@ func (c *Config) ApplyDefaults() {
 		c.UpdatedAt = time.Now().UTC()
 	}
 }
-
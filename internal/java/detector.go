@@ -101,6 +101,30 @@ func (d *Detector) FindByMajorVersion(majorVersion int) (*Installation, error) {
 	return nil, fmt.Errorf("java %d not found", majorVersion)
 }
 
+// FindInRange finds the newest Java installation whose major version is
+// between minMajor and maxMajor (inclusive)
+func (d *Detector) FindInRange(minMajor, maxMajor int) (*Installation, error) {
+	installations, err := d.Detect()
+	if err != nil {
+		return nil, err
+	}
+
+	var best *Installation
+	for _, inst := range installations {
+		if inst.Major < minMajor || inst.Major > maxMajor {
+			continue
+		}
+		if best == nil || inst.Major > best.Major {
+			best = inst
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("java %d-%d not found", minMajor, maxMajor)
+	}
+	return best, nil
+}
+
 // FindBest finds the best Java installation (highest version)
 func (d *Detector) FindBest() (*Installation, error) {
 	installations, err := d.Detect()
@@ -3,15 +3,35 @@ package provider
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackh54/mcinit/internal/cache"
 )
 
-// BungeeProvider implements Provider for BungeeCord
-// Note: BungeeCord doesn't have a proper API, this is a placeholder
-type BungeeProvider struct{}
+// bungeeJenkinsURL is the Jenkins job that publishes BungeeCord builds
+const bungeeJenkinsURL = "https://ci.md-5.net/job/BungeeCord"
+
+// bungeeArtifact is the file name of the proxy jar among the build artifacts
+const bungeeArtifact = "BungeeCord.jar"
+
+// BungeeProvider implements Provider for BungeeCord using the Jenkins JSON
+// API. BungeeCord builds are not tied to a Minecraft version, every build
+// supports the current range of client versions, so the version is ignored
+// and the Jenkins build number is the build.
+type BungeeProvider struct {
+	cache  *cache.Cache
+	client *http.Client
+}
 
 // NewBungeeProvider creates a new BungeeProvider
 func NewBungeeProvider() *BungeeProvider {
-	return &BungeeProvider{}
+	c, _ := cache.New()
+	return &BungeeProvider{
+		cache:  c,
+		client: newHTTPClient(Mirror{"bungee", bungeeJenkinsURL}),
+	}
 }
 
 // GetName returns the provider name
@@ -19,28 +39,122 @@ func (p *BungeeProvider) GetName() string {
 	return "bungee"
 }
 
-// GetAvailableVersions returns error (not supported for BungeeCord)
+// GetAvailableVersions returns an error, BungeeCord builds are not tied to
+// Minecraft versions. Builds are listed through Changes.
 func (p *BungeeProvider) GetAvailableVersions(ctx context.Context) ([]string, error) {
-	return nil, fmt.Errorf("BungeeCord does not have version API support - manual download required")
+	return nil, fmt.Errorf("BungeeCord builds are not tied to Minecraft versions, list them with --builds")
+}
+
+// Changes returns the successful Jenkins builds with their commits, newest
+// first. The version is ignored. Commits of failed builds are listed with the
+// next successful build, as that is the build that ships them.
+func (p *BungeeProvider) Changes(ctx context.Context, version string) ([]BuildChanges, error) {
+	var job JenkinsJob
+	url := bungeeJenkinsURL + "/api/json?tree=builds[number,result,timestamp,changeSet[items[commitId,msg]]]"
+	if err := fetchJSON(ctx, p.client, url, &job); err != nil {
+		return nil, err
+	}
+
+	// Builds are listed newest first
+	var changes []BuildChanges
+	var pending []Commit
+	for i := len(job.Builds) - 1; i >= 0; i-- {
+		build := job.Builds[i]
+		for _, item := range build.ChangeSet.Items {
+			pending = append(pending, Commit{Hash: item.CommitID, Summary: commitSummary(item.Msg)})
+		}
+		if build.Result != "SUCCESS" {
+			continue
+		}
+
+		c := BuildChanges{Build: strconv.Itoa(build.Number), Commits: pending}
+		if build.Timestamp > 0 {
+			c.Time = time.UnixMilli(build.Timestamp).UTC()
+		}
+		changes = append([]BuildChanges{c}, changes...)
+		pending = nil
+	}
+
+	return changes, nil
 }
 
-// GetLatestBuild returns error (not supported)
+// GetLatestBuild returns the last successful build
 func (p *BungeeProvider) GetLatestBuild(ctx context.Context, version string) (string, error) {
-	return "", fmt.Errorf("BungeeCord does not have API support - manual download required")
+	var build JenkinsBuild
+	if err := fetchJSON(ctx, p.client, bungeeJenkinsURL+"/lastSuccessfulBuild/api/json?tree=number", &build); err != nil {
+		return "", err
+	}
+
+	if build.Number == 0 {
+		return "", fmt.Errorf("no successful BungeeCord builds found")
+	}
+	return strconv.Itoa(build.Number), nil
 }
 
-// DownloadJar returns error (not supported)
+// DownloadJar downloads the proxy jar of a build, verified against the MD5
+// fingerprint Jenkins records for it
 func (p *BungeeProvider) DownloadJar(ctx context.Context, version, build string) (string, string, string, error) {
-	return "", "", "", fmt.Errorf("BungeeCord does not have API support - manual download required")
+	info, err := p.getBuild(ctx, version, build)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	downloadURL, err := info.artifactURL(bungeeJenkinsURL, bungeeArtifact)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	checksum, err := info.fingerprint(bungeeArtifact)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	downloader := newDownloader(p.cache, p.client)
+	localPath, err := downloader.DownloadJar(downloadURL, "bungeecord", strconv.Itoa(info.Number), "", checksum, "md5")
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to download jar: %w", err)
+	}
+
+	return localPath, downloadURL, checksum, nil
 }
 
-// GetDownloadURL returns error (not supported)
+// GetDownloadURL returns the URL of the proxy jar of a build
 func (p *BungeeProvider) GetDownloadURL(ctx context.Context, version, build string) (string, error) {
-	return "", fmt.Errorf("BungeeCord does not have API support - manual download required")
+	info, err := p.getBuild(ctx, version, build)
+	if err != nil {
+		return "", err
+	}
+	return info.artifactURL(bungeeJenkinsURL, bungeeArtifact)
 }
 
-// GetChecksum returns error (not supported)
+// GetChecksum returns the MD5 fingerprint of the proxy jar of a build
 func (p *BungeeProvider) GetChecksum(ctx context.Context, version, build string) (string, string, error) {
-	return "", "", fmt.Errorf("BungeeCord does not have API support - manual download required")
+	info, err := p.getBuild(ctx, version, build)
+	if err != nil {
+		return "", "", err
+	}
+
+	checksum, err := info.fingerprint(bungeeArtifact)
+	if err != nil {
+		return "", "", err
+	}
+	return "md5", checksum, nil
 }
 
+// getBuild fetches a build with its artifacts and fingerprints, resolving
+// "latest" to the last successful build
+func (p *BungeeProvider) getBuild(ctx context.Context, version, build string) (*JenkinsBuild, error) {
+	if build == "" || build == "latest" {
+		latest, err := p.GetLatestBuild(ctx, version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get latest build: %w", err)
+		}
+		build = latest
+	}
+
+	if _, err := strconv.Atoi(build); err != nil {
+		return nil, fmt.Errorf("invalid BungeeCord build %q: must be a Jenkins build number", build)
+	}
+
+	return fetchJenkinsBuild(ctx, p.client, bungeeJenkinsURL, build)
+}
@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const bungeeTestJar = "proxy jar"
+
+// newTestBungeeProvider serves a minimal Jenkins job. Builds 1902 and 1900
+// failed and build 1899 has a fingerprint that does not match its jar.
+func newTestBungeeProvider(t *testing.T) *BungeeProvider {
+	t.Helper()
+
+	sum := md5.Sum([]byte(bungeeTestJar))
+	build := func(number int, result, hash string) string {
+		return fmt.Sprintf(`{"number":%d,"result":%q,
+			"artifacts":[{"fileName":"BungeeCord.jar","relativePath":"bootstrap/target/BungeeCord.jar"}],
+			"fingerprint":[{"fileName":"BungeeCord.jar","hash":%q}]}`, number, result, hash)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/job/BungeeCord/api/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"builds":[
+			{"number":1902,"result":"FAILURE","changeSet":{"items":[{"commitId":"d4","msg":"Break the build"}]}},
+			{"number":1901,"result":"SUCCESS","timestamp":1735732800000,"changeSet":{"items":[{"commitId":"c3","msg":"Fix the build"}]}},
+			{"number":1900,"result":"FAILURE","changeSet":{"items":[{"commitId":"b2","msg":"Add 1.21.4 support\n\nDetails"}]}},
+			{"number":1899,"result":"SUCCESS","changeSet":{"items":[{"commitId":"a1","msg":"Update dependencies"}]}}]}`)
+	})
+	mux.HandleFunc("/job/BungeeCord/lastSuccessfulBuild/api/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"number":1901}`)
+	})
+	mux.HandleFunc("/job/BungeeCord/1901/api/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, build(1901, "SUCCESS", hex.EncodeToString(sum[:])))
+	})
+	mux.HandleFunc("/job/BungeeCord/1902/api/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, build(1902, "FAILURE", ""))
+	})
+	mux.HandleFunc("/job/BungeeCord/1899/api/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, build(1899, "SUCCESS", "00000000000000000000000000000000"))
+	})
+	for _, number := range []string{"1901", "1899"} {
+		mux.HandleFunc("/job/BungeeCord/"+number+"/artifact/bootstrap/target/BungeeCord.jar", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, bungeeTestJar)
+		})
+	}
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	t.Setenv("MCINIT_BUNGEE_URL", server.URL+"/job/BungeeCord")
+
+	p := NewBungeeProvider()
+	p.cache = useTestCache(t)
+	return p
+}
+
+func TestBungeeBuilds(t *testing.T) {
+	p := newTestBungeeProvider(t)
+	ctx := context.Background()
+
+	if _, err := p.GetAvailableVersions(ctx); err == nil {
+		t.Error("GetAvailableVersions() should fail, BungeeCord has no version list")
+	}
+
+	latest, err := p.GetLatestBuild(ctx, "1.21.4")
+	if err != nil || latest != "1901" {
+		t.Errorf("GetLatestBuild() = %s, %v, want 1901", latest, err)
+	}
+
+	url, err := p.GetDownloadURL(ctx, "1.21.4", "latest")
+	if err != nil || !strings.HasSuffix(url, "/job/BungeeCord/1901/artifact/bootstrap/target/BungeeCord.jar") {
+		t.Errorf("GetDownloadURL() = %s, %v", url, err)
+	}
+
+	algorithm, checksum, err := p.GetChecksum(ctx, "1.21.4", "1901")
+	if err != nil || algorithm != "md5" || len(checksum) != 32 {
+		t.Errorf("GetChecksum() = %s:%s, %v", algorithm, checksum, err)
+	}
+
+	for _, build := range []string{"1902", "not-a-number"} {
+		if _, err := p.GetDownloadURL(ctx, "1.21.4", build); err == nil {
+			t.Errorf("GetDownloadURL(%s) should fail", build)
+		}
+	}
+}
+
+func TestBungeeDownloadJar(t *testing.T) {
+	p := newTestBungeeProvider(t)
+	ctx := context.Background()
+
+	localPath, _, checksum, err := p.DownloadJar(ctx, "1.21.4", "latest")
+	if err != nil {
+		t.Fatalf("DownloadJar() error = %v", err)
+	}
+	data, err := os.ReadFile(localPath)
+	if err != nil || string(data) != bungeeTestJar {
+		t.Errorf("Downloaded jar = %q, %v", data, err)
+	}
+	if checksum == "" {
+		t.Error("DownloadJar() returned no checksum")
+	}
+
+	if _, _, _, err := p.DownloadJar(ctx, "1.21.4", "1899"); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("DownloadJar() with a wrong fingerprint error = %v, want checksum mismatch", err)
+	}
+}
+
+func TestBungeeChanges(t *testing.T) {
+	p := newTestBungeeProvider(t)
+
+	changes, err := p.Changes(context.Background(), "1.21.4")
+	if err != nil {
+		t.Fatalf("Changes() error = %v", err)
+	}
+
+	want := []BuildChanges{
+		{
+			Build: "1901",
+			Time:  time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+			Commits: []Commit{
+				{Hash: "b2", Summary: "Add 1.21.4 support"},
+				{Hash: "c3", Summary: "Fix the build"},
+			},
+		},
+		{Build: "1899", Commits: []Commit{{Hash: "a1", Summary: "Update dependencies"}}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Changes() = %+v, want %+v", changes, want)
+	}
+}
@@ -0,0 +1,277 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jackh54/mcinit/internal/cache"
+	"github.com/jackh54/mcinit/internal/utils"
+)
+
+// customJarName is the name of the installed custom jar in the server directory
+const customJarName = "server.jar"
+
+// CustomProvider implements Provider for a server jar supplied by the user,
+// such as the build output of an in-house fork or a jar on an internal
+// server. The registered provider has no source, use WithSource.
+type CustomProvider struct {
+	cache     *cache.Cache
+	source    string // local path or http(s) URL
+	algorithm string // of the optional expected checksum
+	checksum  string
+	link      bool // link local jars instead of copying them
+
+	symlink func(oldname, newname string) error
+	warn    io.Writer // receives warnings such as a link that fell back to a copy
+}
+
+// NewCustomProvider creates a new CustomProvider without a source
+func NewCustomProvider() *CustomProvider {
+	c, _ := cache.New()
+	return &CustomProvider{cache: c, symlink: os.Symlink, warn: os.Stderr}
+}
+
+// WithSource returns a copy of the provider for a jar at a local path or
+// URL. The checksum is optional, see ParseChecksum. Local jars are linked
+// into the server directory instead of copied if link is set.
+func (p *CustomProvider) WithSource(source, checksum string, link bool) (*CustomProvider, error) {
+	if source == "" {
+		return nil, fmt.Errorf("custom server jar path or URL is required")
+	}
+
+	custom := *p
+	custom.source = source
+	custom.link = link
+
+	if checksum != "" {
+		algorithm, sum, err := ParseChecksum(checksum)
+		if err != nil {
+			return nil, err
+		}
+		custom.algorithm = algorithm
+		custom.checksum = sum
+	}
+
+	if link && custom.isURL() {
+		return nil, fmt.Errorf("only local jars can be linked, not %s", source)
+	}
+
+	return &custom, nil
+}
+
+// GetName returns the provider name
+func (p *CustomProvider) GetName() string {
+	return "custom"
+}
+
+// GetAvailableVersions returns an error, custom jars have no version list
+func (p *CustomProvider) GetAvailableVersions(ctx context.Context) ([]string, error) {
+	return nil, fmt.Errorf("custom jars do not have a version list")
+}
+
+// GetLatestBuild returns empty string (custom jars have no builds)
+func (p *CustomProvider) GetLatestBuild(ctx context.Context, version string) (string, error) {
+	return "", nil
+}
+
+// DownloadJar puts the jar into the cache, downloading it if the source is
+// a URL, and verifies the expected checksum if there is one. It returns the
+// cached path, the source URL (empty for local jars) and the checksum.
+func (p *CustomProvider) DownloadJar(ctx context.Context, version, build string) (string, string, string, error) {
+	if p.source == "" {
+		return "", "", "", fmt.Errorf("custom server jar path or URL is required")
+	}
+
+	if p.isURL() {
+		return p.download(version)
+	}
+
+	if err := p.verify(p.source); err != nil {
+		return "", "", "", err
+	}
+
+	sum, err := cache.FileChecksum(p.source, "sha256")
+	if err != nil {
+		return "", "", "", err
+	}
+
+	// Keyed by content, so every build of a fork gets its own entry
+	localPath, err := p.cache.StoreJar(p.source, "custom", version, sum[:12], "")
+	if err != nil {
+		return "", "", "", err
+	}
+	return localPath, "", sum, nil
+}
+
+// GetDownloadURL returns the source URL, local jars have none
+func (p *CustomProvider) GetDownloadURL(ctx context.Context, version, build string) (string, error) {
+	if !p.isURL() {
+		return "", fmt.Errorf("local jar %s has no download URL", p.source)
+	}
+	return p.source, nil
+}
+
+// GetChecksum returns the expected checksum, or the SHA-256 checksum of a
+// local jar if none was given
+func (p *CustomProvider) GetChecksum(ctx context.Context, version, build string) (string, string, error) {
+	if p.checksum != "" {
+		return p.algorithm, p.checksum, nil
+	}
+	if p.isURL() {
+		return "", "", fmt.Errorf("no checksum given for %s", p.source)
+	}
+
+	sum, err := cache.FileChecksum(p.source, "sha256")
+	if err != nil {
+		return "", "", err
+	}
+	return "sha256", sum, nil
+}
+
+// Install copies or links the jar into serverDir as server.jar and records
+// its SHA-256 checksum
+func (p *CustomProvider) Install(ctx context.Context, serverDir, javaPath, version, build string) (*InstallResult, error) {
+	target := filepath.Join(serverDir, customJarName)
+	if p.link {
+		return p.linkJar(target)
+	}
+
+	localPath, downloadURL, _, err := p.DownloadJar(ctx, version, build)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := utils.CopyFile(localPath, target); err != nil {
+		return nil, fmt.Errorf("failed to copy server jar: %w", err)
+	}
+
+	sum, err := cache.FileChecksum(localPath, "sha256")
+	if err != nil {
+		return nil, err
+	}
+
+	return &InstallResult{
+		JarPath:     customJarName,
+		DownloadURL: downloadURL,
+		Checksum:    sum,
+		Algorithm:   "sha256",
+	}, nil
+}
+
+// linkJar links target to the local jar, so rebuilding the jar updates the
+// server without running init again. Where symlinks are not permitted, such
+// as on Windows without Developer Mode, the jar is copied instead.
+func (p *CustomProvider) linkJar(target string) (*InstallResult, error) {
+	source, err := filepath.Abs(p.source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve jar path: %w", err)
+	}
+
+	if err := p.verify(source); err != nil {
+		return nil, err
+	}
+
+	sum, err := cache.FileChecksum(source, "sha256")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to replace server jar: %w", err)
+	}
+	if err := p.symlink(source, target); err != nil {
+		if err := utils.CopyFile(source, target); err != nil {
+			return nil, fmt.Errorf("failed to copy server jar: %w", err)
+		}
+		fmt.Fprintf(p.warn, "[WARN] Failed to link server jar, copied it instead (copy it again after rebuilding it): %v\n", err)
+	}
+
+	return &InstallResult{
+		JarPath:   customJarName,
+		Checksum:  sum,
+		Algorithm: "sha256",
+	}, nil
+}
+
+// download downloads a jar from the source URL into the cache
+func (p *CustomProvider) download(version string) (string, string, string, error) {
+	// A checksum identifies the jar, without one the URL may serve a
+	// different jar every time and the cached copy cannot be trusted
+	key := "url-" + shortHash(p.source)
+	if p.checksum != "" {
+		key = p.checksum[:12]
+	} else if err := p.cache.RemoveJar("custom", version, key); err != nil {
+		return "", "", "", err
+	}
+
+	downloader := cache.NewDownloader(p.cache)
+	localPath, err := downloader.DownloadJar(p.source, "custom", version, key, p.checksum, p.algorithm)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to download jar: %w", err)
+	}
+
+	return localPath, p.source, p.checksum, nil
+}
+
+// verify checks a local jar against the expected checksum, if there is one
+func (p *CustomProvider) verify(path string) error {
+	if p.checksum == "" {
+		return nil
+	}
+
+	sum, err := cache.FileChecksum(path, p.algorithm)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(sum, p.checksum) {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", path, p.checksum, sum)
+	}
+	return nil
+}
+
+// isURL reports whether the source is downloaded rather than a local file
+func (p *CustomProvider) isURL() bool {
+	return strings.HasPrefix(p.source, "http://") || strings.HasPrefix(p.source, "https://")
+}
+
+// shortHash returns a short hex digest of a string for cache keys
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:6])
+}
+
+// ParseChecksum parses a checksum given as "algorithm:hex", or as bare hex
+// whose algorithm is inferred from its length
+func ParseChecksum(value string) (string, string, error) {
+	algorithm, sum, found := strings.Cut(value, ":")
+	if !found {
+		sum = value
+		switch len(value) {
+		case 64:
+			algorithm = "sha256"
+		case 40:
+			algorithm = "sha1"
+		case 32:
+			algorithm = "md5"
+		default:
+			return "", "", fmt.Errorf("cannot tell the algorithm of checksum %s (use sha256:, sha1: or md5:)", value)
+		}
+	}
+
+	algorithm = strings.ToLower(algorithm)
+	wantLength := map[string]int{"sha256": 64, "sha1": 40, "md5": 32}[algorithm]
+	if wantLength == 0 {
+		return "", "", fmt.Errorf("unsupported checksum algorithm: %s", algorithm)
+	}
+	if len(sum) != wantLength || strings.Trim(strings.ToLower(sum), "0123456789abcdef") != "" {
+		return "", "", fmt.Errorf("invalid %s checksum: %s", algorithm, sum)
+	}
+
+	return algorithm, strings.ToLower(sum), nil
+}
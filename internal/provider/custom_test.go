@@ -0,0 +1,238 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newTestCustomProvider returns a provider for source with an empty cache
+func newTestCustomProvider(t *testing.T, source, checksum string, link bool) *CustomProvider {
+	t.Helper()
+
+	base := NewCustomProvider()
+	base.cache = useTestCache(t)
+	p, err := base.WithSource(source, checksum, link)
+	if err != nil {
+		t.Fatalf("WithSource() error = %v", err)
+	}
+	return p
+}
+
+// writeTestJar writes a jar with the given content and returns its path and SHA-256
+func writeTestJar(t *testing.T, content string) (string, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "fork.jar")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(content))
+	return path, hex.EncodeToString(sum[:])
+}
+
+func TestParseChecksum(t *testing.T) {
+	sha256Sum := strings.Repeat("ab", 32)
+	sha1Sum := strings.Repeat("cd", 20)
+	md5Sum := strings.Repeat("ef", 16)
+
+	tests := []struct {
+		name          string
+		value         string
+		wantAlgorithm string
+		wantSum       string
+		wantErr       bool
+	}{
+		{"prefixed sha256", "sha256:" + sha256Sum, "sha256", sha256Sum, false},
+		{"uppercase", "SHA1:" + strings.ToUpper(sha1Sum), "sha1", sha1Sum, false},
+		{"bare sha256", sha256Sum, "sha256", sha256Sum, false},
+		{"bare sha1", sha1Sum, "sha1", sha1Sum, false},
+		{"bare md5", md5Sum, "md5", md5Sum, false},
+		{"unknown length", "abc123", "", "", true},
+		{"unsupported algorithm", "crc32:deadbeef", "", "", true},
+		{"wrong length", "sha256:" + sha1Sum, "", "", true},
+		{"not hex", "md5:" + strings.Repeat("zz", 16), "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algorithm, sum, err := ParseChecksum(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseChecksum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if algorithm != tt.wantAlgorithm || sum != tt.wantSum {
+				t.Errorf("ParseChecksum() = %s, %s, want %s, %s", algorithm, sum, tt.wantAlgorithm, tt.wantSum)
+			}
+		})
+	}
+}
+
+func TestCustomWithSource(t *testing.T) {
+	p := NewCustomProvider()
+
+	if _, err := p.WithSource("", "", false); err == nil {
+		t.Error("WithSource() without a source should fail")
+	}
+	if _, err := p.WithSource("https://example.com/server.jar", "", true); err == nil {
+		t.Error("WithSource() should not link a URL")
+	}
+	if _, err := p.WithSource("server.jar", "sha256:abc", false); err == nil {
+		t.Error("WithSource() should reject an invalid checksum")
+	}
+}
+
+func TestCustomInstallCopy(t *testing.T) {
+	source, sum := writeTestJar(t, "fork build 1")
+	p := newTestCustomProvider(t, source, "sha256:"+sum, false)
+	serverDir := t.TempDir()
+
+	result, err := p.Install(context.Background(), serverDir, "java", "1.21.4", "")
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	if result.JarPath != "server.jar" || result.Checksum != sum || result.Algorithm != "sha256" {
+		t.Errorf("Install() = %+v, want server.jar with sha256 %s", result, sum)
+	}
+
+	data, err := os.ReadFile(filepath.Join(serverDir, "server.jar"))
+	if err != nil || string(data) != "fork build 1" {
+		t.Errorf("server.jar = %q, %v, want the source jar", data, err)
+	}
+
+	if !p.cache.HasJar("custom", "1.21.4", sum[:12]) {
+		t.Error("Install() should cache the jar by its checksum")
+	}
+
+	// Rebuilding the fork must not affect the installed copy
+	if err := os.WriteFile(source, []byte("fork build 2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(filepath.Join(serverDir, "server.jar"))
+	if string(data) != "fork build 1" {
+		t.Errorf("server.jar changed with its source: %q", data)
+	}
+}
+
+func TestCustomInstallChecksumMismatch(t *testing.T) {
+	source, _ := writeTestJar(t, "fork build 1")
+	p := newTestCustomProvider(t, source, "sha256:"+strings.Repeat("0", 64), false)
+
+	_, err := p.Install(context.Background(), t.TempDir(), "java", "1.21.4", "")
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Install() error = %v, want a checksum mismatch", err)
+	}
+}
+
+func TestCustomInstallLink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require privileges on Windows")
+	}
+
+	source, sum := writeTestJar(t, "fork build 1")
+	p := newTestCustomProvider(t, source, "", true)
+	serverDir := t.TempDir()
+
+	result, err := p.Install(context.Background(), serverDir, "java", "1.21.4", "")
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if result.Checksum != sum {
+		t.Errorf("Install() checksum = %s, want %s", result.Checksum, sum)
+	}
+
+	target, err := os.Readlink(filepath.Join(serverDir, "server.jar"))
+	if err != nil || target != source {
+		t.Errorf("server.jar links to %q, %v, want %s", target, err, source)
+	}
+}
+
+func TestCustomInstallLinkFallback(t *testing.T) {
+	source, sum := writeTestJar(t, "fork build 1")
+	p := newTestCustomProvider(t, source, "", true)
+	p.symlink = func(oldname, newname string) error {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: os.ErrPermission}
+	}
+	var warnings strings.Builder
+	p.warn = &warnings
+	serverDir := t.TempDir()
+
+	result, err := p.Install(context.Background(), serverDir, "java", "1.21.4", "")
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if result.Checksum != sum {
+		t.Errorf("Install() checksum = %s, want %s", result.Checksum, sum)
+	}
+
+	data, err := os.ReadFile(filepath.Join(serverDir, "server.jar"))
+	if err != nil || string(data) != "fork build 1" {
+		t.Errorf("server.jar = %q, %v, want a copy of the jar", data, err)
+	}
+	if !strings.Contains(warnings.String(), "copied it instead") {
+		t.Errorf("warnings = %q, want the fallback reported", warnings.String())
+	}
+}
+
+func TestCustomDownloadURL(t *testing.T) {
+	var served atomic.Value
+	served.Store("internal build 1")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, served.Load())
+	}))
+	t.Cleanup(server.Close)
+
+	jarURL := server.URL + "/server.jar"
+	ctx := context.Background()
+
+	t.Run("with checksum", func(t *testing.T) {
+		content := served.Load().(string)
+		sum := sha256.Sum256([]byte(content))
+		p := newTestCustomProvider(t, jarURL, hex.EncodeToString(sum[:]), false)
+
+		localPath, downloadURL, checksum, err := p.DownloadJar(ctx, "1.21.4", "")
+		if err != nil {
+			t.Fatalf("DownloadJar() error = %v", err)
+		}
+		if downloadURL != jarURL || checksum != hex.EncodeToString(sum[:]) {
+			t.Errorf("DownloadJar() = %s, %s", downloadURL, checksum)
+		}
+		if data, _ := os.ReadFile(localPath); string(data) != content {
+			t.Errorf("downloaded jar = %q, want %q", data, content)
+		}
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		p := newTestCustomProvider(t, jarURL, "sha256:"+strings.Repeat("0", 64), false)
+		if _, _, _, err := p.DownloadJar(ctx, "1.21.4", ""); err == nil {
+			t.Error("DownloadJar() should fail on a checksum mismatch")
+		}
+	})
+
+	t.Run("without checksum", func(t *testing.T) {
+		p := newTestCustomProvider(t, jarURL, "", false)
+
+		if _, _, _, err := p.DownloadJar(ctx, "1.21.4", ""); err != nil {
+			t.Fatalf("DownloadJar() error = %v", err)
+		}
+
+		// Without a checksum the jar is downloaded again, not taken from the cache
+		served.Store("internal build 2")
+		localPath, _, _, err := p.DownloadJar(ctx, "1.21.4", "")
+		if err != nil {
+			t.Fatalf("DownloadJar() error = %v", err)
+		}
+		if data, _ := os.ReadFile(localPath); string(data) != "internal build 2" {
+			t.Errorf("downloaded jar = %q, want the new build", data)
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/jackh54/mcinit/internal/cache"
+)
+
+// fabricMetaURL is the base URL of the Fabric meta API
+const fabricMetaURL = "https://meta.fabricmc.net"
+
+// FabricProvider implements Provider for Fabric. Builds combine a loader and
+// an installer version, see LoaderBuild.
+type FabricProvider struct {
+	cache  *cache.Cache
+	client *http.Client
+}
+
+// NewFabricProvider creates a new FabricProvider
+func NewFabricProvider() *FabricProvider {
+	c, _ := cache.New()
+	return &FabricProvider{
+		cache:  c,
+		client: newHTTPClient(Mirror{"fabric", fabricMetaURL}),
+	}
+}
+
+// GetName returns the provider name
+func (p *FabricProvider) GetName() string {
+	return "fabric"
+}
+
+// GetAvailableVersions returns the stable Minecraft versions supported by Fabric
+func (p *FabricProvider) GetAvailableVersions(ctx context.Context) ([]string, error) {
+	var games []LoaderVersion
+	if err := fetchJSON(ctx, p.client, fabricMetaURL+"/v2/versions/game", &games); err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(games))
+	for _, game := range games {
+		if game.Stable {
+			versions = append(versions, game.Version)
+		}
+	}
+
+	return versions, nil
+}
+
+// GetLatestBuild returns the latest stable loader and installer for a version
+func (p *FabricProvider) GetLatestBuild(ctx context.Context, version string) (string, error) {
+	loader, installer, err := p.ResolveLoader(ctx, version, "", "")
+	if err != nil {
+		return "", err
+	}
+	return p.LoaderBuild(loader, installer), nil
+}
+
+// ResolveLoader resolves the loader and installer versions for a Minecraft
+// version. Empty or "latest" versions resolve to the latest stable release.
+func (p *FabricProvider) ResolveLoader(ctx context.Context, version, loader, installer string) (string, string, error) {
+	loaders, err := p.getLoaders(ctx, version)
+	if err != nil {
+		return "", "", err
+	}
+
+	if loader == "" || loader == "latest" {
+		loader = latestLoaderVersion(loaders)
+	} else if !hasLoaderVersion(loaders, loader) {
+		return "", "", fmt.Errorf("fabric loader %s is not available for Minecraft %s", loader, version)
+	}
+
+	installers, err := p.getInstallers(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	if installer == "" || installer == "latest" {
+		installer = latestLoaderVersion(installers)
+		if installer == "" {
+			return "", "", fmt.Errorf("no Fabric installer versions available")
+		}
+	} else if !hasLoaderVersion(installers, installer) {
+		return "", "", fmt.Errorf("fabric installer %s not found", installer)
+	}
+
+	return loader, installer, nil
+}
+
+// LoaderBuild encodes a loader and installer version as a build
+func (p *FabricProvider) LoaderBuild(loader, installer string) string {
+	return joinLoaderBuild(loader, installer)
+}
+
+// ServerJarName returns the launcher jar name. The launcher downloads the
+// vanilla server as server.jar on first start.
+func (p *FabricProvider) ServerJarName() string {
+	return "fabric-server-launch.jar"
+}
+
+// SplitLoaderBuild decodes a build created by LoaderBuild
+func (p *FabricProvider) SplitLoaderBuild(build string) (string, string, error) {
+	return splitLoaderBuild("Fabric", build)
+}
+
+// DownloadJar downloads the Fabric server launcher jar
+func (p *FabricProvider) DownloadJar(ctx context.Context, version, build string) (string, string, string, error) {
+	build, err := p.resolveBuild(ctx, version, build)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	downloadURL, err := p.GetDownloadURL(ctx, version, build)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	// The meta API does not publish checksums for the launcher jar
+	downloader := newDownloader(p.cache, p.client)
+	localPath, err := downloader.DownloadJar(downloadURL, "fabric", version, build, "", "")
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to download jar: %w", err)
+	}
+
+	return localPath, downloadURL, "", nil
+}
+
+// GetDownloadURL returns the URL of the server launcher jar
+func (p *FabricProvider) GetDownloadURL(ctx context.Context, version, build string) (string, error) {
+	build, err := p.resolveBuild(ctx, version, build)
+	if err != nil {
+		return "", err
+	}
+
+	loader, installer, err := p.SplitLoaderBuild(build)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/v2/versions/loader/%s/%s/%s/server/jar", fabricMetaURL,
+		url.PathEscape(version), url.PathEscape(loader), url.PathEscape(installer)), nil
+}
+
+// GetChecksum returns no checksum, the meta API does not publish one
+func (p *FabricProvider) GetChecksum(ctx context.Context, version, build string) (string, string, error) {
+	return "", "", nil
+}
+
+// resolveBuild resolves "latest" to the latest loader and installer
+func (p *FabricProvider) resolveBuild(ctx context.Context, version, build string) (string, error) {
+	if build != "" && build != "latest" {
+		return build, nil
+	}
+
+	latest, err := p.GetLatestBuild(ctx, version)
+	if err != nil {
+		return "", fmt.Errorf("failed to get latest build: %w", err)
+	}
+	return latest, nil
+}
+
+// getLoaders returns the loader versions for a Minecraft version, newest first
+func (p *FabricProvider) getLoaders(ctx context.Context, version string) ([]LoaderVersion, error) {
+	var entries []FabricLoaderEntry
+	if err := fetchJSON(ctx, p.client, fabricMetaURL+"/v2/versions/loader/"+url.PathEscape(version), &entries); err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("fabric does not support Minecraft %s", version)
+	}
+
+	loaders := make([]LoaderVersion, 0, len(entries))
+	for _, entry := range entries {
+		loaders = append(loaders, entry.Loader)
+	}
+	return loaders, nil
+}
+
+// getInstallers returns the installer versions, newest first
+func (p *FabricProvider) getInstallers(ctx context.Context) ([]LoaderVersion, error) {
+	var installers []LoaderVersion
+	if err := fetchJSON(ctx, p.client, fabricMetaURL+"/v2/versions/installer", &installers); err != nil {
+		return nil, err
+	}
+	return installers, nil
+}
+
+// Fabric meta API structures
+
+type FabricLoaderEntry struct {
+	Loader LoaderVersion `json:"loader"`
+}
@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// newFabricTestServer serves a minimal Fabric meta API
+func newFabricTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/versions/game", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"version":"1.21.5-pre1","stable":false},{"version":"1.21.4","stable":true},{"version":"1.21.3","stable":true}]`)
+	})
+	mux.HandleFunc("/v2/versions/loader/1.21.4", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"loader":{"version":"0.17.0-beta.1","stable":false}},{"loader":{"version":"0.16.10","stable":true}},{"loader":{"version":"0.16.9","stable":true}}]`)
+	})
+	mux.HandleFunc("/v2/versions/loader/1.0", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/v2/versions/installer", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"version":"1.1.0","stable":false},{"version":"1.0.1","stable":true}]`)
+	})
+	mux.HandleFunc("/v2/versions/loader/1.21.4/0.16.10/1.0.1/server/jar", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "launcher jar")
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestFabricProvider(t *testing.T) *FabricProvider {
+	t.Helper()
+
+	t.Setenv("MCINIT_FABRIC_URL", newFabricTestServer(t).URL)
+
+	p := NewFabricProvider()
+	p.cache = useTestCache(t)
+	return p
+}
+
+func TestFabricAvailableVersions(t *testing.T) {
+	p := newTestFabricProvider(t)
+
+	versions, err := p.GetAvailableVersions(context.Background())
+	if err != nil {
+		t.Fatalf("GetAvailableVersions() error = %v", err)
+	}
+	if len(versions) != 2 || versions[0] != "1.21.4" {
+		t.Errorf("GetAvailableVersions() = %v, want stable versions only", versions)
+	}
+}
+
+func TestFabricResolveLoader(t *testing.T) {
+	p := newTestFabricProvider(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		version       string
+		loader        string
+		installer     string
+		wantLoader    string
+		wantInstaller string
+		wantErr       bool
+	}{
+		{"latest stable", "1.21.4", "latest", "", "0.16.10", "1.0.1", false},
+		{"pinned", "1.21.4", "0.16.9", "1.1.0", "0.16.9", "1.1.0", false},
+		{"unknown loader", "1.21.4", "0.1.0", "", "", "", true},
+		{"unknown installer", "1.21.4", "", "9.9.9", "", "", true},
+		{"unsupported version", "1.0", "", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader, installer, err := p.ResolveLoader(ctx, tt.version, tt.loader, tt.installer)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveLoader() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if loader != tt.wantLoader || installer != tt.wantInstaller {
+				t.Errorf("ResolveLoader() = (%s, %s), want (%s, %s)", loader, installer, tt.wantLoader, tt.wantInstaller)
+			}
+		})
+	}
+}
+
+func TestFabricLoaderBuild(t *testing.T) {
+	p := NewFabricProvider()
+
+	build := p.LoaderBuild("0.4.8+build.155", "0.11.2")
+	loader, installer, err := p.SplitLoaderBuild(build)
+	if err != nil {
+		t.Fatalf("SplitLoaderBuild() error = %v", err)
+	}
+	if loader != "0.4.8+build.155" || installer != "0.11.2" {
+		t.Errorf("SplitLoaderBuild(%q) = (%s, %s)", build, loader, installer)
+	}
+
+	for _, invalid := range []string{"", "0.16.10", "-1.0.1", "0.16.10-"} {
+		if _, _, err := p.SplitLoaderBuild(invalid); err == nil {
+			t.Errorf("SplitLoaderBuild(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestFabricDownloadJar(t *testing.T) {
+	p := newTestFabricProvider(t)
+
+	localPath, downloadURL, _, err := p.DownloadJar(context.Background(), "1.21.4", "latest")
+	if err != nil {
+		t.Fatalf("DownloadJar() error = %v", err)
+	}
+
+	want := fabricMetaURL + "/v2/versions/loader/1.21.4/0.16.10/1.0.1/server/jar"
+	if downloadURL != want {
+		t.Errorf("DownloadJar() URL = %s, want %s", downloadURL, want)
+	}
+
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		t.Fatalf("Failed to read downloaded jar: %v", err)
+	}
+	if string(data) != "launcher jar" {
+		t.Errorf("Downloaded jar = %q", data)
+	}
+}
@@ -0,0 +1,338 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jackh54/mcinit/internal/cache"
+	"github.com/jackh54/mcinit/internal/utils"
+)
+
+// Maven repositories of the Forge and NeoForge artifacts
+const (
+	forgeMavenURL    = "https://maven.minecraftforge.net/net/minecraftforge/forge"
+	neoForgeMavenURL = "https://maven.neoforged.net/releases/net/neoforged/neoforge"
+)
+
+// unixArgsFile is the argument file written by modern installers. A
+// win_args.txt for Windows is written next to it.
+const unixArgsFile = "unix_args.txt"
+
+// ForgeProvider implements Provider for Forge and NeoForge, which share an
+// installer format. DownloadJar returns the installer jar, Install runs it
+// with --installServer. Modern versions are launched through the argument
+// file the installer writes under libraries/ instead of a server jar.
+type ForgeProvider struct {
+	cache   *cache.Cache
+	client  *http.Client
+	name    string
+	baseURL string // Maven repository directory of the artifact
+
+	// librariesPath is where the installer puts the artifact under libraries/
+	librariesPath string
+
+	// versionPrefix returns the prefix of artifact versions for a Minecraft version
+	versionPrefix func(mcVersion string) string
+
+	// mcVersion returns the Minecraft version of an artifact version
+	mcVersion func(version string) string
+
+	// build and artifactVersion convert between builds and artifact versions
+	build           func(mcVersion, version string) string
+	artifactVersion func(mcVersion, build string) string
+}
+
+// NewForgeProvider creates a provider for Forge. Artifact versions combine
+// the Minecraft and Forge versions (1.21.4-54.0.26), builds are the latter.
+func NewForgeProvider() *ForgeProvider {
+	return newForgeProvider("forge", forgeMavenURL, "net/minecraftforge/forge")
+}
+
+// NewNeoForgeProvider creates a provider for NeoForge. Artifact versions
+// drop the leading "1." of the Minecraft version (21.4.10-beta for 1.21.4)
+// and are used as builds unchanged.
+func NewNeoForgeProvider() *ForgeProvider {
+	p := newForgeProvider("neoforge", neoForgeMavenURL, "net/neoforged/neoforge")
+	p.versionPrefix = neoForgeVersionPrefix
+	p.mcVersion = neoForgeMCVersion
+	p.build = func(_, version string) string { return version }
+	p.artifactVersion = func(_, build string) string { return build }
+	return p
+}
+
+func newForgeProvider(name, baseURL, librariesPath string) *ForgeProvider {
+	c, _ := cache.New()
+	return &ForgeProvider{
+		cache:         c,
+		name:          name,
+		baseURL:       baseURL,
+		librariesPath: librariesPath,
+		versionPrefix: func(mcVersion string) string { return mcVersion + "-" },
+		mcVersion: func(version string) string {
+			mc, _, _ := strings.Cut(version, "-")
+			return mc
+		},
+		build: func(mcVersion, version string) string {
+			return strings.TrimPrefix(version, mcVersion+"-")
+		},
+		artifactVersion: func(mcVersion, build string) string {
+			return mcVersion + "-" + build
+		},
+		client: newHTTPClient(Mirror{name, baseURL}),
+	}
+}
+
+// GetName returns the provider name
+func (p *ForgeProvider) GetName() string {
+	return p.name
+}
+
+// GetAvailableVersions returns the Minecraft versions with a release, oldest first
+func (p *ForgeProvider) GetAvailableVersions(ctx context.Context) ([]string, error) {
+	versions, err := p.getVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var mcVersions []string
+	for _, version := range versions {
+		mc := p.mcVersion(version)
+		if mc == "" || seen[mc] {
+			continue
+		}
+		seen[mc] = true
+		mcVersions = append(mcVersions, mc)
+	}
+
+	sort.SliceStable(mcVersions, func(i, j int) bool {
+		return compareVersions(mcVersions[i], mcVersions[j]) < 0
+	})
+	return mcVersions, nil
+}
+
+// GetLatestBuild returns the latest build for a version, preferring
+// releases over beta builds
+func (p *ForgeProvider) GetLatestBuild(ctx context.Context, version string) (string, error) {
+	versions, err := p.versionsFor(ctx, version)
+	if err != nil {
+		return "", err
+	}
+
+	latest := versions[len(versions)-1]
+	for i := len(versions) - 1; i >= 0; i-- {
+		if !isPreRelease(versions[i]) {
+			latest = versions[i]
+			break
+		}
+	}
+
+	return p.build(version, latest), nil
+}
+
+// DownloadJar downloads the installer jar for a build
+func (p *ForgeProvider) DownloadJar(ctx context.Context, version, build string) (string, string, string, error) {
+	artifact, err := p.resolveArtifact(ctx, version, build)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	downloadURL := p.installerURL(artifact)
+	checksum, err := p.installerChecksum(ctx, downloadURL)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	downloader := newDownloader(p.cache, p.client)
+	localPath, err := downloader.DownloadJar(downloadURL, p.name+"-installer", artifact, "", checksum, "sha1")
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to download installer: %w", err)
+	}
+
+	return localPath, downloadURL, checksum, nil
+}
+
+// GetDownloadURL returns the URL of the installer jar
+func (p *ForgeProvider) GetDownloadURL(ctx context.Context, version, build string) (string, error) {
+	artifact, err := p.resolveArtifact(ctx, version, build)
+	if err != nil {
+		return "", err
+	}
+	return p.installerURL(artifact), nil
+}
+
+// GetChecksum returns the SHA-1 checksum published next to the installer jar
+func (p *ForgeProvider) GetChecksum(ctx context.Context, version, build string) (string, string, error) {
+	downloadURL, err := p.GetDownloadURL(ctx, version, build)
+	if err != nil {
+		return "", "", err
+	}
+
+	checksum, err := p.installerChecksum(ctx, downloadURL)
+	if err != nil {
+		return "", "", err
+	}
+	return "sha1", checksum, nil
+}
+
+// Install runs the installer with --installServer in serverDir. The
+// installer downloads the vanilla server and libraries and writes the
+// argument files used to launch the server.
+func (p *ForgeProvider) Install(ctx context.Context, serverDir, javaPath, version, build string) (*InstallResult, error) {
+	artifact, err := p.resolveArtifact(ctx, version, build)
+	if err != nil {
+		return nil, err
+	}
+
+	installerPath, downloadURL, checksum, err := p.DownloadJar(ctx, version, p.build(version, artifact))
+	if err != nil {
+		return nil, err
+	}
+
+	absDir, err := filepath.Abs(serverDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve server directory: %w", err)
+	}
+
+	if err := runInstaller(ctx, javaPath, absDir, installerPath, "--installServer", absDir); err != nil {
+		return nil, err
+	}
+
+	result := &InstallResult{
+		DownloadURL: downloadURL,
+		Checksum:    checksum,
+		Algorithm:   "sha1",
+	}
+
+	// Minecraft 1.17 and later are launched through an argument file
+	argsFile := path.Join("libraries", p.librariesPath, artifact, unixArgsFile)
+	if utils.PathExists(filepath.Join(absDir, filepath.FromSlash(argsFile))) {
+		result.ArgsFile = argsFile
+		return result, nil
+	}
+
+	// Older installers write an executable server jar
+	for _, name := range []string{
+		fmt.Sprintf("%s-%s.jar", p.name, artifact),
+		fmt.Sprintf("%s-%s-universal.jar", p.name, artifact),
+	} {
+		if utils.PathExists(filepath.Join(absDir, name)) {
+			result.JarPath = name
+			return result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("installer did not create %s or a server jar", argsFile)
+}
+
+// resolveArtifact resolves "latest" and returns the artifact version of a build
+func (p *ForgeProvider) resolveArtifact(ctx context.Context, version, build string) (string, error) {
+	if build == "" || build == "latest" {
+		latest, err := p.GetLatestBuild(ctx, version)
+		if err != nil {
+			return "", fmt.Errorf("failed to get latest build: %w", err)
+		}
+		build = latest
+	}
+
+	artifact := p.artifactVersion(version, build)
+	if !strings.HasPrefix(artifact, p.versionPrefix(version)) {
+		return "", fmt.Errorf("%s build %s is not for Minecraft %s", p.name, build, version)
+	}
+	return artifact, nil
+}
+
+// installerURL returns the URL of the installer jar of an artifact version
+func (p *ForgeProvider) installerURL(artifact string) string {
+	return fmt.Sprintf("%s/%s/%s-%s-installer.jar", p.baseURL, artifact, path.Base(p.librariesPath), artifact)
+}
+
+// installerChecksum fetches the SHA-1 checksum published next to an installer
+func (p *ForgeProvider) installerChecksum(ctx context.Context, downloadURL string) (string, error) {
+	text, err := fetchText(ctx, p.client, downloadURL+".sha1")
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch installer checksum: %w", err)
+	}
+
+	// Checksum files may be followed by the file name
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty installer checksum")
+	}
+	return fields[0], nil
+}
+
+// versionsFor returns the artifact versions for a Minecraft version, oldest
+// first. Maven metadata does not guarantee an order, Forge lists newest first.
+func (p *ForgeProvider) versionsFor(ctx context.Context, mcVersion string) ([]string, error) {
+	versions, err := p.getVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := p.versionPrefix(mcVersion)
+	var matching []string
+	for _, version := range versions {
+		if strings.HasPrefix(version, prefix) {
+			matching = append(matching, version)
+		}
+	}
+
+	if len(matching) == 0 {
+		return nil, fmt.Errorf("%s does not support Minecraft %s", p.name, mcVersion)
+	}
+
+	sort.SliceStable(matching, func(i, j int) bool {
+		return compareVersions(p.build(mcVersion, matching[i]), p.build(mcVersion, matching[j])) < 0
+	})
+	return matching, nil
+}
+
+// getVersions returns all artifact versions listed in the Maven metadata
+func (p *ForgeProvider) getVersions(ctx context.Context) ([]string, error) {
+	metadata, err := fetchMavenMetadata(ctx, p.client, p.baseURL+"/maven-metadata.xml")
+	if err != nil {
+		return nil, err
+	}
+	return metadata.Versioning.Versions, nil
+}
+
+// isPreRelease reports whether an artifact version is a beta or alpha build
+func isPreRelease(version string) bool {
+	lower := strings.ToLower(version)
+	return strings.Contains(lower, "beta") || strings.Contains(lower, "alpha")
+}
+
+// neoForgeVersionPrefix maps a Minecraft version to the prefix of its
+// NeoForge versions: 1.21.4 to "21.4." and 1.21 to "21.0."
+func neoForgeVersionPrefix(mcVersion string) string {
+	trimmed := strings.TrimPrefix(mcVersion, "1.")
+	if trimmed == mcVersion {
+		// Versions that no longer start with "1." are used as is
+		return mcVersion + "."
+	}
+
+	if !strings.Contains(trimmed, ".") {
+		trimmed += ".0"
+	}
+	return trimmed + "."
+}
+
+// neoForgeMCVersion maps a NeoForge version to its Minecraft version, the
+// inverse of neoForgeVersionPrefix
+func neoForgeMCVersion(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 3 {
+		return ""
+	}
+
+	if parts[1] == "0" {
+		return "1." + parts[0]
+	}
+	return "1." + parts[0] + "." + parts[1]
+}
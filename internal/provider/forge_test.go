@@ -0,0 +1,285 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const forgeTestInstaller = "installer jar"
+
+// newForgeTestServer serves maven metadata listing versions and an
+// installer for every version
+func newForgeTestServer(t *testing.T, artifact string, versions ...string) *httptest.Server {
+	t.Helper()
+
+	sum := sha1.Sum([]byte(forgeTestInstaller))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/maven-metadata.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <groupId>net.example</groupId>
+  <artifactId>%s</artifactId>
+  <versioning>
+    <versions>
+      <version>%s</version>
+    </versions>
+  </versioning>
+</metadata>`, artifact, strings.Join(versions, "</version>\n      <version>"))
+	})
+	for _, version := range versions {
+		installer := fmt.Sprintf("/%s/%s-%s-installer.jar", version, artifact, version)
+		mux.HandleFunc(installer, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, forgeTestInstaller)
+		})
+		mux.HandleFunc(installer+".sha1", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, hex.EncodeToString(sum[:]))
+		})
+	}
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestForgeProvider(t *testing.T, p *ForgeProvider, versions ...string) *ForgeProvider {
+	t.Helper()
+
+	t.Setenv(MirrorEnv(p.name), newForgeTestServer(t, p.name, versions...).URL)
+
+	p.cache = useTestCache(t)
+	return p
+}
+
+func TestForgeVersions(t *testing.T) {
+	p := newTestForgeProvider(t, NewForgeProvider(),
+		"1.20.1-47.3.0", "1.21.4-54.0.25", "1.21.4-54.0.26", "1.7.10-10.13.4.1614-1.7.10")
+	ctx := context.Background()
+
+	versions, err := p.GetAvailableVersions(ctx)
+	if err != nil {
+		t.Fatalf("GetAvailableVersions() error = %v", err)
+	}
+	if want := []string{"1.7.10", "1.20.1", "1.21.4"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("GetAvailableVersions() = %v, want %v", versions, want)
+	}
+
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{"1.21.4", "54.0.26", false},
+		{"1.7.10", "10.13.4.1614-1.7.10", false},
+		{"1.21", "", true},
+	}
+	for _, tt := range tests {
+		build, err := p.GetLatestBuild(ctx, tt.version)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("GetLatestBuild(%s) error = %v, wantErr %v", tt.version, err, tt.wantErr)
+			continue
+		}
+		if build != tt.want {
+			t.Errorf("GetLatestBuild(%s) = %s, want %s", tt.version, build, tt.want)
+		}
+	}
+
+	url, err := p.GetDownloadURL(ctx, "1.21.4", "54.0.25")
+	if err != nil {
+		t.Fatalf("GetDownloadURL() error = %v", err)
+	}
+	if !strings.HasSuffix(url, "/1.21.4-54.0.25/forge-1.21.4-54.0.25-installer.jar") {
+		t.Errorf("GetDownloadURL() = %s", url)
+	}
+}
+
+func TestForgeVersionsOutOfOrder(t *testing.T) {
+	// Forge's metadata lists newest first
+	p := newTestForgeProvider(t, NewForgeProvider(),
+		"1.21.4-54.1.0", "1.21.4-54.0.100", "1.21.4-54.0.9", "1.20.1-47.3.0")
+
+	build, err := p.GetLatestBuild(context.Background(), "1.21.4")
+	if err != nil || build != "54.1.0" {
+		t.Errorf("GetLatestBuild() = %s, %v, want 54.1.0", build, err)
+	}
+
+	p = newTestForgeProvider(t, NewNeoForgeProvider(),
+		"21.4.12-beta", "21.4.100", "21.4.9", "21.1.90")
+
+	build, err = p.GetLatestBuild(context.Background(), "1.21.4")
+	if err != nil || build != "21.4.100" {
+		t.Errorf("GetLatestBuild() = %s, %v, want 21.4.100", build, err)
+	}
+}
+
+func TestNeoForgeVersions(t *testing.T) {
+	p := newTestForgeProvider(t, NewNeoForgeProvider(),
+		"21.0.167", "21.4.10-beta", "21.4.11-beta", "21.1.90", "21.1.91-beta")
+	ctx := context.Background()
+
+	versions, err := p.GetAvailableVersions(ctx)
+	if err != nil {
+		t.Fatalf("GetAvailableVersions() error = %v", err)
+	}
+	if want := []string{"1.21", "1.21.1", "1.21.4"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("GetAvailableVersions() = %v, want %v", versions, want)
+	}
+
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"1.21", "21.0.167"},
+		{"1.21.1", "21.1.90"},      // releases are preferred
+		{"1.21.4", "21.4.11-beta"}, // beta builds are used if there is nothing else
+	}
+	for _, tt := range tests {
+		build, err := p.GetLatestBuild(ctx, tt.version)
+		if err != nil {
+			t.Errorf("GetLatestBuild(%s) error = %v", tt.version, err)
+			continue
+		}
+		if build != tt.want {
+			t.Errorf("GetLatestBuild(%s) = %s, want %s", tt.version, build, tt.want)
+		}
+	}
+
+	if _, err := p.GetDownloadURL(ctx, "1.21.4", "21.1.90"); err == nil {
+		t.Error("GetDownloadURL() with a build of another Minecraft version should fail")
+	}
+}
+
+func TestNeoForgeVersionPrefix(t *testing.T) {
+	tests := []struct {
+		mcVersion string
+		want      string
+	}{
+		{"1.21.4", "21.4."},
+		{"1.21", "21.0."},
+		{"1.20.2", "20.2."},
+	}
+	for _, tt := range tests {
+		if got := neoForgeVersionPrefix(tt.mcVersion); got != tt.want {
+			t.Errorf("neoForgeVersionPrefix(%s) = %s, want %s", tt.mcVersion, got, tt.want)
+		}
+		if got := neoForgeMCVersion(tt.want + "10"); got != tt.mcVersion {
+			t.Errorf("neoForgeMCVersion(%s10) = %s, want %s", tt.want, got, tt.mcVersion)
+		}
+	}
+}
+
+// writeFakeInstaller writes a fake java that records its arguments and runs
+// script in the directory given to --installServer
+func writeFakeInstaller(t *testing.T, script string) (string, string) {
+	t.Helper()
+
+	binDir := t.TempDir()
+	javaPath := filepath.Join(binDir, "java")
+	argsPath := filepath.Join(binDir, "args")
+	content := `#!/bin/sh
+echo "$@" > "` + argsPath + `"
+while [ "$1" != "--installServer" ]; do shift; done
+cd "$2" || exit 1
+` + script
+	if err := os.WriteFile(javaPath, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to write fake java: %v", err)
+	}
+	return javaPath, argsPath
+}
+
+func TestForgeInstall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake installer is a shell script")
+	}
+
+	tests := []struct {
+		name         string
+		provider     *ForgeProvider
+		version      string
+		build        string
+		artifact     string
+		script       string
+		wantArgsFile string
+		wantJar      string
+	}{
+		{
+			name:         "forge argument file",
+			provider:     NewForgeProvider(),
+			version:      "1.21.4",
+			build:        "54.0.26",
+			artifact:     "1.21.4-54.0.26",
+			script:       "mkdir -p libraries/net/minecraftforge/forge/1.21.4-54.0.26 && touch libraries/net/minecraftforge/forge/1.21.4-54.0.26/unix_args.txt\n",
+			wantArgsFile: "libraries/net/minecraftforge/forge/1.21.4-54.0.26/unix_args.txt",
+		},
+		{
+			name:         "neoforge argument file",
+			provider:     NewNeoForgeProvider(),
+			version:      "1.21.1",
+			build:        "latest",
+			artifact:     "21.1.90",
+			script:       "mkdir -p libraries/net/neoforged/neoforge/21.1.90 && touch libraries/net/neoforged/neoforge/21.1.90/unix_args.txt\n",
+			wantArgsFile: "libraries/net/neoforged/neoforge/21.1.90/unix_args.txt",
+		},
+		{
+			name:     "legacy forge jar",
+			provider: NewForgeProvider(),
+			version:  "1.12.2",
+			build:    "14.23.5.2860",
+			artifact: "1.12.2-14.23.5.2860",
+			script:   "touch forge-1.12.2-14.23.5.2860.jar\n",
+			wantJar:  "forge-1.12.2-14.23.5.2860.jar",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestForgeProvider(t, tt.provider, tt.artifact)
+			javaPath, argsPath := writeFakeInstaller(t, tt.script)
+
+			serverDir := t.TempDir()
+			result, err := p.Install(context.Background(), serverDir, javaPath, tt.version, tt.build)
+			if err != nil {
+				t.Fatalf("Install() error = %v", err)
+			}
+
+			if result.ArgsFile != tt.wantArgsFile || result.JarPath != tt.wantJar {
+				t.Errorf("Install() = args file %q, jar %q, want %q, %q",
+					result.ArgsFile, result.JarPath, tt.wantArgsFile, tt.wantJar)
+			}
+			if result.Algorithm != "sha1" || result.Checksum == "" {
+				t.Errorf("Install() checksum = %s:%s", result.Algorithm, result.Checksum)
+			}
+
+			args, err := os.ReadFile(argsPath)
+			if err != nil {
+				t.Fatalf("Installer was not run: %v", err)
+			}
+			if !strings.Contains(string(args), "--installServer "+serverDir) {
+				t.Errorf("Installer args = %q", args)
+			}
+		})
+	}
+}
+
+func TestForgeInstallWithoutOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake installer is a shell script")
+	}
+
+	p := newTestForgeProvider(t, NewForgeProvider(), "1.21.4-54.0.26")
+	javaPath, _ := writeFakeInstaller(t, "echo 'Nothing to do'\n")
+
+	_, err := p.Install(context.Background(), t.TempDir(), javaPath, "1.21.4", "54.0.26")
+	if err == nil || !strings.Contains(err.Error(), "unix_args.txt") {
+		t.Errorf("Install() error = %v, want missing argument file", err)
+	}
+}
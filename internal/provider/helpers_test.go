@@ -0,0 +1,19 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/jackh54/mcinit/internal/cache"
+)
+
+// useTestCache points the provider cache at an empty directory
+func useTestCache(t *testing.T) *cache.Cache {
+	t.Helper()
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	c, err := cache.New()
+	if err != nil {
+		t.Fatalf("cache.New() error = %v", err)
+	}
+	return c
+}
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// fetchBytes fetches a URL and returns the response body
+func fetchBytes(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// fetchJSON fetches a URL and decodes the JSON response into v
+func fetchJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	body, err := fetchBytes(ctx, client, url)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse response from %s: %w", url, err)
+	}
+
+	return nil
+}
+
+// fetchText fetches a small text resource such as a checksum file
+func fetchText(ctx context.Context, client *http.Client, url string) (string, error) {
+	body, err := fetchBytes(ctx, client, url)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// runInstaller runs an installer jar in serverDir. The output is only shown
+// when the installer fails.
+func runInstaller(ctx context.Context, javaPath, serverDir, installerPath string, args ...string) error {
+	return streamInstaller(ctx, javaPath, serverDir, installerPath, nil, args...)
+}
+
+// streamInstaller runs an installer jar in dir like runInstaller, copying its
+// output to progress as it runs when progress is not nil
+func streamInstaller(ctx context.Context, javaPath, dir, installerPath string, progress io.Writer, args ...string) error {
+	cmdArgs := append([]string{"-jar", installerPath}, args...)
+	cmd := exec.CommandContext(ctx, javaPath, cmdArgs...)
+	cmd.Dir = dir
+
+	var output bytes.Buffer
+	var writer io.Writer = &output
+	if progress != nil {
+		writer = io.MultiWriter(&output, progress)
+	}
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("installer failed: %w%s", err, outputTail(output.String(), 20))
+	}
+	return nil
+}
+
+// outputTail returns the last lines of command output formatted for an error message
+func outputTail(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	if len(lines) == 0 || (len(lines) == 1 && lines[0] == "") {
+		return ""
+	}
+	return ":\n  " + strings.Join(lines, "\n  ")
+}
@@ -2,6 +2,8 @@ package provider
 
 import (
 	"context"
+	"strings"
+	"time"
 )
 
 // Provider defines the interface for server jar providers
@@ -27,12 +29,123 @@ type Provider interface {
 	GetName() string
 }
 
+// LoaderProvider is implemented by mod loader providers, whose builds combine
+// a loader version and an installer version
+type LoaderProvider interface {
+	Provider
+
+	// ResolveLoader resolves the loader and installer versions for a
+	// Minecraft version. Empty or "latest" resolves to the latest stable one.
+	ResolveLoader(ctx context.Context, version, loader, installer string) (loaderVersion, installerVersion string, err error)
+
+	// LoaderBuild encodes a loader and installer version as a build
+	LoaderBuild(loader, installer string) string
+
+	// ServerJarName returns the file name of the server launcher jar, which
+	// must not clash with the vanilla server.jar it loads
+	ServerJarName() string
+}
+
+// BuildResolver is implemented by providers with build keywords other than
+// "latest" or whose builds target different plugin API versions
+type BuildResolver interface {
+	Provider
+
+	// ResolveBuild resolves a build keyword such as "latest" or
+	// "recommended" for a Minecraft version, limited to builds for
+	// apiVersion if it is not empty. Other builds are validated.
+	ResolveBuild(ctx context.Context, version, apiVersion, build string) (string, error)
+}
+
+// BuildChannelResolver is implemented by providers that publish builds on
+// channels of different stability, such as PaperMC's experimental builds
+type BuildChannelResolver interface {
+	// BuildChannels returns the channel names, from stable to least stable
+	BuildChannels() []string
+
+	// ResolveChannelBuild resolves "latest" to the newest build allowed by
+	// the channel preference and returns the build with its channel
+	ResolveChannelBuild(ctx context.Context, version, channel, build string) (string, string, error)
+}
+
+// JavaRequirer is implemented by providers whose metadata names the Java
+// version a server needs
+type JavaRequirer interface {
+	// RequiredJava returns the minimum Java major version for a Minecraft
+	// version, or 0 if the metadata does not name one
+	RequiredJava(ctx context.Context, version string) (int, error)
+}
+
+// VersionResolver is implemented by providers that accept version aliases
+// such as "latest-release"
+type VersionResolver interface {
+	// ResolveVersion resolves a version alias to a Minecraft version and
+	// returns other versions unchanged
+	ResolveVersion(ctx context.Context, version string) (string, error)
+}
+
+// ChannelLister is implemented by providers that publish versions on
+// several channels, such as snapshots next to releases
+type ChannelLister interface {
+	// Channels returns the channel names, including "all"
+	Channels() []string
+
+	// GetVersionsInChannel returns the versions published on a channel
+	GetVersionsInChannel(ctx context.Context, channel string) ([]string, error)
+}
+
+// Installer is implemented by providers whose server is set up by running an
+// installer in the server directory instead of copying a single jar
+type Installer interface {
+	// Install downloads and runs the installer for the given version/build
+	// in serverDir, using javaPath to run it
+	Install(ctx context.Context, serverDir, javaPath, version, build string) (*InstallResult, error)
+}
+
+// InstallResult describes a server set up by an Installer
+type InstallResult struct {
+	JarPath     string // launch jar, relative to the server directory
+	ArgsFile    string // launch argument file used instead of a jar, if any
+	DownloadURL string // installer download URL
+	Checksum    string // installer checksum, or the server jar's if the installer builds it
+	Algorithm   string // "sha1", "sha256" or "md5", empty if unverified
+}
+
+// ChangeLister is implemented by providers whose build metadata lists the
+// commits each build brings
+type ChangeLister interface {
+	// Changes returns the builds of a version with their commits, newest
+	// first
+	Changes(ctx context.Context, version string) ([]BuildChanges, error)
+}
+
+// BuildChanges describes a build and the commits it brings
+type BuildChanges struct {
+	Build   string
+	Time    time.Time // zero if unknown
+	Channel string    // e.g. "default" or "experimental", empty if not published
+	Failed  bool      // the build failed and has no jar to install
+	Commits []Commit
+}
+
+// Commit is a commit included in a build
+type Commit struct {
+	Hash    string
+	Summary string // first line of the commit message
+}
+
+// commitSummary returns the first line of a commit message
+func commitSummary(message string) string {
+	summary, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return strings.TrimSpace(summary)
+}
+
 // BuildInfo represents build information for a server
 type BuildInfo struct {
 	Version     string
 	Build       string
 	DownloadURL string
 	Checksum    string
-	Algorithm   string // "sha1" or "sha256"
+	Algorithm   string // "sha1", "sha256" or "md5"
 }
 
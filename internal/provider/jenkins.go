@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// jenkinsBuildTree selects the build fields used from the Jenkins JSON API
+const jenkinsBuildTree = "number,result,artifacts[fileName,relativePath],fingerprint[fileName,hash]"
+
+// Jenkins JSON API structures
+
+type JenkinsJob struct {
+	Builds []JenkinsBuild `json:"builds"`
+}
+
+type JenkinsBuild struct {
+	Number    int    `json:"number"`
+	Result    string `json:"result"`
+	Timestamp int64  `json:"timestamp"` // Unix milliseconds
+	ChangeSet struct {
+		Items []struct {
+			CommitID string `json:"commitId"`
+			Msg      string `json:"msg"`
+		} `json:"items"`
+	} `json:"changeSet"`
+	Artifacts []struct {
+		FileName     string `json:"fileName"`
+		RelativePath string `json:"relativePath"`
+	} `json:"artifacts"`
+	Fingerprint []struct {
+		FileName string `json:"fileName"`
+		Hash     string `json:"hash"` // MD5
+	} `json:"fingerprint"`
+}
+
+// fetchJenkinsBuild fetches a successful build of a Jenkins job. The build
+// is a build number or a permalink such as "lastSuccessfulBuild".
+func fetchJenkinsBuild(ctx context.Context, client *http.Client, jobURL, build string) (*JenkinsBuild, error) {
+	var info JenkinsBuild
+	url := fmt.Sprintf("%s/%s/api/json?tree=%s", jobURL, build, jenkinsBuildTree)
+	if err := fetchJSON(ctx, client, url, &info); err != nil {
+		return nil, fmt.Errorf("failed to get build info: %w", err)
+	}
+
+	if info.Result != "SUCCESS" {
+		return nil, fmt.Errorf("build %s did not succeed (%s)", build, info.Result)
+	}
+	return &info, nil
+}
+
+// artifactURL returns the download URL of an artifact of the build
+func (b *JenkinsBuild) artifactURL(jobURL, fileName string) (string, error) {
+	for _, artifact := range b.Artifacts {
+		if artifact.FileName == fileName {
+			return fmt.Sprintf("%s/%d/artifact/%s", jobURL, b.Number, artifact.RelativePath), nil
+		}
+	}
+	return "", fmt.Errorf("build %d has no %s artifact", b.Number, fileName)
+}
+
+// fingerprint returns the MD5 hash Jenkins recorded for an artifact
+func (b *JenkinsBuild) fingerprint(fileName string) (string, error) {
+	for _, fp := range b.Fingerprint {
+		if fp.FileName == fileName && fp.Hash != "" {
+			return fp.Hash, nil
+		}
+	}
+	return "", fmt.Errorf("no fingerprint recorded for %s in build %d", fileName, b.Number)
+}
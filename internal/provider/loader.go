@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LoaderVersion is a game, loader or installer version listed by a mod
+// loader meta API
+type LoaderVersion struct {
+	Version string `json:"version"`
+	Stable  bool   `json:"stable"`
+}
+
+// latestLoaderVersion returns the newest stable version, or the newest
+// version if none is stable. Versions are listed newest first.
+func latestLoaderVersion(versions []LoaderVersion) string {
+	for _, v := range versions {
+		if v.Stable {
+			return v.Version
+		}
+	}
+	if len(versions) > 0 {
+		return versions[0].Version
+	}
+	return ""
+}
+
+// hasLoaderVersion checks if a version is listed
+func hasLoaderVersion(versions []LoaderVersion, version string) bool {
+	for _, v := range versions {
+		if v.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// joinLoaderBuild encodes a loader and installer version as a build
+func joinLoaderBuild(loader, installer string) string {
+	return loader + "-" + installer
+}
+
+// splitLoaderBuild decodes a build created by joinLoaderBuild. Loader
+// versions may contain dashes (0.28.0-beta.1), installer versions do not.
+func splitLoaderBuild(name, build string) (string, string, error) {
+	i := strings.LastIndex(build, "-")
+	if i <= 0 || i == len(build)-1 {
+		return "", "", fmt.Errorf("invalid %s build %q (expected <loader>-<installer>)", name, build)
+	}
+	return build[:i], build[i+1:], nil
+}
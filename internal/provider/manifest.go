@@ -0,0 +1,472 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackh54/mcinit/internal/cache"
+)
+
+// Manifest declares a provider for a distribution with a JSON API, so new
+// server types can be added without a release. Endpoint URLs are templates
+// in which {version} and {build} are replaced, and paths select values from
+// the JSON responses (see selectJSON). An example for a PaperMC-style API:
+//
+//	{
+//	  "name": "leaf",
+//	  "versions": {"url": "https://api.example.org/v2/projects/leaf", "path": "versions"},
+//	  "builds": {"url": "https://api.example.org/v2/projects/leaf/versions/{version}", "path": "builds[-1]"},
+//	  "download": {"url": "https://api.example.org/v2/projects/leaf/versions/{version}/builds/{build}/downloads/leaf-{version}-{build}.jar"},
+//	  "checksum": {"url": "https://api.example.org/v2/projects/leaf/versions/{version}/builds/{build}", "path": "downloads.primary.sha256", "algorithm": "sha256"}
+//	}
+type Manifest struct {
+	Name     string            `json:"name"` // defaults to the file name
+	Versions ManifestEndpoint  `json:"versions"`
+	Builds   *ManifestEndpoint `json:"builds,omitempty"` // omitted if there are no builds
+	Download ManifestEndpoint  `json:"download"`
+	Checksum *ManifestChecksum `json:"checksum,omitempty"`
+}
+
+// ManifestEndpoint is a URL template and the path of a value in its response.
+// Without a path the URL itself is the value for downloads, and the response
+// is read as text for checksums.
+type ManifestEndpoint struct {
+	URL  string `json:"url"`
+	Path string `json:"path,omitempty"`
+}
+
+// ManifestChecksum locates the checksum of a jar. The URL defaults to the
+// download endpoint's, for APIs that publish the checksum with the jar URL.
+type ManifestChecksum struct {
+	ManifestEndpoint
+	Algorithm string `json:"algorithm"` // "sha256", "sha1" or "md5"
+}
+
+// manifestNamePattern matches valid provider names
+var manifestNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Validate checks that the manifest defines a usable provider
+func (m *Manifest) Validate() error {
+	if !manifestNamePattern.MatchString(m.Name) {
+		return fmt.Errorf("invalid provider name %q (use lowercase letters, digits, - and _)", m.Name)
+	}
+	if m.Versions.URL == "" || m.Versions.Path == "" {
+		return fmt.Errorf("versions requires a url and a path")
+	}
+	if m.Builds != nil && (m.Builds.URL == "" || m.Builds.Path == "") {
+		return fmt.Errorf("builds requires a url and a path")
+	}
+	if m.Download.URL == "" {
+		return fmt.Errorf("download requires a url")
+	}
+	if m.Builds == nil && strings.Contains(m.Download.URL, "{build}") {
+		return fmt.Errorf("download url uses {build} but no builds are defined")
+	}
+
+	if m.Checksum != nil {
+		switch m.Checksum.Algorithm {
+		case "sha256", "sha1", "md5":
+		default:
+			return fmt.Errorf("unsupported checksum algorithm: %q", m.Checksum.Algorithm)
+		}
+		if m.Checksum.URL == "" && (m.Checksum.Path == "" || m.Download.Path == "") {
+			return fmt.Errorf("checksum requires a url unless it is selected from the download response")
+		}
+	}
+
+	return nil
+}
+
+// ManifestProvider implements Provider for a distribution declared by a Manifest
+type ManifestProvider struct {
+	cache    *cache.Cache
+	client   *http.Client
+	manifest Manifest
+}
+
+// NewManifestProvider creates a provider for a validated manifest
+func NewManifestProvider(manifest Manifest) *ManifestProvider {
+	c, _ := cache.New()
+	return &ManifestProvider{
+		cache:    c,
+		manifest: manifest,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// ProvidersDir returns the directory manifests are loaded from,
+// mcinit/providers in the user's configuration directory
+func ProvidersDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "mcinit", "providers"), nil
+}
+
+// LoadManifests registers a provider for every *.json manifest in dir and
+// returns their names. A missing directory is not an error. Invalid
+// manifests and manifests that would replace a registered provider are
+// skipped and reported in the returned error.
+func LoadManifests(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var loaded []string
+	var errs []string
+	for _, path := range paths {
+		manifest, err := ReadManifest(path)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if Exists(manifest.Name) {
+			errs = append(errs, fmt.Sprintf("%s: provider %s is already registered", path, manifest.Name))
+			continue
+		}
+
+		Register(manifest.Name, NewManifestProvider(*manifest))
+		loaded = append(loaded, manifest.Name)
+	}
+
+	if len(errs) > 0 {
+		return loaded, fmt.Errorf("failed to load provider manifests:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return loaded, nil
+}
+
+// ReadManifest reads and validates a manifest file
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var manifest Manifest
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if manifest.Name == "" {
+		manifest.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := manifest.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &manifest, nil
+}
+
+// GetName returns the provider name
+func (p *ManifestProvider) GetName() string {
+	return p.manifest.Name
+}
+
+// GetAvailableVersions returns the versions selected from the versions endpoint
+func (p *ManifestProvider) GetAvailableVersions(ctx context.Context) ([]string, error) {
+	values, err := p.query(ctx, p.manifest.Versions, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// GetLatestBuild returns the build selected from the builds endpoint, the
+// last one if the path selects several. Manifests without builds return an
+// empty string.
+func (p *ManifestProvider) GetLatestBuild(ctx context.Context, version string) (string, error) {
+	if p.manifest.Builds == nil {
+		return "", nil
+	}
+
+	builds, err := p.query(ctx, *p.manifest.Builds, version, "")
+	if err != nil {
+		return "", err
+	}
+	if len(builds) == 0 {
+		return "", fmt.Errorf("no %s builds found for %s", p.manifest.Name, version)
+	}
+	return builds[len(builds)-1], nil
+}
+
+// DownloadJar downloads the jar of a build, verified if the manifest
+// declares a checksum
+func (p *ManifestProvider) DownloadJar(ctx context.Context, version, build string) (string, string, string, error) {
+	build, err := p.resolveBuild(ctx, version, build)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	downloadURL, err := p.GetDownloadURL(ctx, version, build)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	algorithm, checksum, err := p.GetChecksum(ctx, version, build)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	downloader := cache.NewDownloader(p.cache)
+	localPath, err := downloader.DownloadJar(downloadURL, p.manifest.Name, version, build, checksum, algorithm)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to download jar: %w", err)
+	}
+
+	return localPath, downloadURL, checksum, nil
+}
+
+// GetDownloadURL returns the jar URL of a build
+func (p *ManifestProvider) GetDownloadURL(ctx context.Context, version, build string) (string, error) {
+	build, err := p.resolveBuild(ctx, version, build)
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := p.manifest.Download
+	if endpoint.Path == "" {
+		return expandManifestURL(endpoint.URL, version, build), nil
+	}
+
+	value, err := p.queryOne(ctx, endpoint, version, build)
+	if err != nil {
+		return "", err
+	}
+
+	// Selected URLs may be relative to the endpoint
+	base, err := url.Parse(expandManifestURL(endpoint.URL, version, build))
+	if err != nil {
+		return "", fmt.Errorf("invalid download url: %w", err)
+	}
+	ref, err := url.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid download url %q: %w", value, err)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// GetChecksum returns the checksum of a build, or empty strings if the
+// manifest declares none
+func (p *ManifestProvider) GetChecksum(ctx context.Context, version, build string) (string, string, error) {
+	checksum := p.manifest.Checksum
+	if checksum == nil {
+		return "", "", nil
+	}
+
+	build, err := p.resolveBuild(ctx, version, build)
+	if err != nil {
+		return "", "", err
+	}
+
+	endpoint := checksum.ManifestEndpoint
+	if endpoint.URL == "" {
+		endpoint.URL = p.manifest.Download.URL
+	}
+
+	if endpoint.Path == "" {
+		text, err := fetchText(ctx, p.client, expandManifestURL(endpoint.URL, version, build))
+		if err != nil {
+			return "", "", fmt.Errorf("failed to fetch checksum: %w", err)
+		}
+		// Checksum files may be followed by the file name
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			return "", "", fmt.Errorf("empty checksum")
+		}
+		return checksum.Algorithm, fields[0], nil
+	}
+
+	value, err := p.queryOne(ctx, endpoint, version, build)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get checksum: %w", err)
+	}
+	return checksum.Algorithm, value, nil
+}
+
+// resolveBuild resolves "latest" to the latest build
+func (p *ManifestProvider) resolveBuild(ctx context.Context, version, build string) (string, error) {
+	if build != "" && build != "latest" {
+		return build, nil
+	}
+
+	latest, err := p.GetLatestBuild(ctx, version)
+	if err != nil {
+		return "", fmt.Errorf("failed to get latest build: %w", err)
+	}
+	return latest, nil
+}
+
+// query fetches an endpoint and returns the values its path selects
+func (p *ManifestProvider) query(ctx context.Context, endpoint ManifestEndpoint, version, build string) ([]string, error) {
+	endpointURL := expandManifestURL(endpoint.URL, version, build)
+	body, err := fetchBytes(ctx, p.client, endpointURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse response from %s: %w", endpointURL, err)
+	}
+
+	values, err := selectJSON(doc, endpoint.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select %s from %s: %w", endpoint.Path, endpointURL, err)
+	}
+	return values, nil
+}
+
+// queryOne is query for paths that must select a single value
+func (p *ManifestProvider) queryOne(ctx context.Context, endpoint ManifestEndpoint, version, build string) (string, error) {
+	values, err := p.query(ctx, endpoint, version, build)
+	if err != nil {
+		return "", err
+	}
+	if len(values) != 1 {
+		return "", fmt.Errorf("%s selects %d values, expected one", endpoint.Path, len(values))
+	}
+	return values[0], nil
+}
+
+// expandManifestURL replaces {version} and {build} in a URL template
+func expandManifestURL(template, version, build string) string {
+	return strings.NewReplacer(
+		"{version}", url.PathEscape(version),
+		"{build}", url.PathEscape(build),
+	).Replace(template)
+}
+
+// selectorTokenPattern matches the parts of a selector: a key, optionally
+// followed by indexes such as [0], [-1] or [*]
+var selectorTokenPattern = regexp.MustCompile(`^([^.\[\]]*)((?:\[(?:-?\d+|\*)\])*)$`)
+
+// selectorIndexPattern matches a single index of a selector part
+var selectorIndexPattern = regexp.MustCompile(`\[(-?\d+|\*)\]`)
+
+// selectJSON selects values from a decoded JSON document with a JSONPath-like
+// path: dot-separated keys, array indexes counted from the end if negative,
+// and [*] for every element. An optional leading "$." is ignored. A selected
+// array yields its elements, objects cannot be selected.
+func selectJSON(doc interface{}, path string) ([]string, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+
+	nodes := []interface{}{doc}
+	if path != "" {
+		for _, part := range strings.Split(path, ".") {
+			match := selectorTokenPattern.FindStringSubmatch(part)
+			if match == nil || (match[1] == "" && match[2] == "") {
+				return nil, fmt.Errorf("invalid selector %q", part)
+			}
+
+			var err error
+			if match[1] != "" {
+				if nodes, err = selectKey(nodes, match[1]); err != nil {
+					return nil, err
+				}
+			}
+			for _, index := range selectorIndexPattern.FindAllStringSubmatch(match[2], -1) {
+				if nodes, err = selectIndex(nodes, index[1]); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	var values []string
+	for _, node := range nodes {
+		// Arrays at the end of the path are lists of values
+		if elements, ok := node.([]interface{}); ok {
+			for _, element := range elements {
+				value, err := scalarString(element)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, value)
+			}
+			continue
+		}
+
+		value, err := scalarString(node)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// selectKey selects a key of every node
+func selectKey(nodes []interface{}, key string) ([]interface{}, error) {
+	selected := make([]interface{}, 0, len(nodes))
+	for _, node := range nodes {
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot select %q from a non-object", key)
+		}
+		value, ok := object[key]
+		if !ok {
+			return nil, fmt.Errorf("key %q not found", key)
+		}
+		selected = append(selected, value)
+	}
+	return selected, nil
+}
+
+// selectIndex selects an element, or every element for "*", of every node
+func selectIndex(nodes []interface{}, index string) ([]interface{}, error) {
+	var selected []interface{}
+	for _, node := range nodes {
+		elements, ok := node.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot index a non-array with [%s]", index)
+		}
+
+		if index == "*" {
+			selected = append(selected, elements...)
+			continue
+		}
+
+		i, _ := strconv.Atoi(index)
+		if i < 0 {
+			i += len(elements)
+		}
+		if i < 0 || i >= len(elements) {
+			return nil, fmt.Errorf("index [%s] out of range for %d elements", index, len(elements))
+		}
+		selected = append(selected, elements[i])
+	}
+	return selected, nil
+}
+
+// scalarString converts a selected JSON string, number or boolean to a string
+func scalarString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("selected value is not a string or number")
+	}
+}
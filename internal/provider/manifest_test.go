@@ -0,0 +1,258 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const manifestTestJar = "fork server jar"
+
+// newManifestTestServer serves a PaperMC-style API with builds 7 and 8 of 1.21.4
+func newManifestTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	sum := sha256.Sum256([]byte(manifestTestJar))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/projects/fork", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"project_id":"fork","versions":["1.21.3","1.21.4"]}`)
+	})
+	mux.HandleFunc("/v2/projects/fork/versions/1.21.4", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"builds":[7,8]}`)
+	})
+	mux.HandleFunc("/v2/projects/fork/versions/1.21.4/builds/8", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"downloads":{"application":{"url":"/jars/fork-1.21.4-8.jar","sha256":%q}}}`, hex.EncodeToString(sum[:]))
+	})
+	mux.HandleFunc("/jars/fork-1.21.4-8.jar", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, manifestTestJar)
+	})
+	mux.HandleFunc("/jars/fork-1.21.4-8.jar.sha256", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  fork-1.21.4-8.jar\n", hex.EncodeToString(sum[:]))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestManifestProvider returns a provider for the manifest with an empty cache
+func newTestManifestProvider(t *testing.T, manifest Manifest) *ManifestProvider {
+	t.Helper()
+
+	if err := manifest.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	p := NewManifestProvider(manifest)
+	p.cache = useTestCache(t)
+	return p
+}
+
+func TestSelectJSON(t *testing.T) {
+	var doc interface{}
+	decoder := json.NewDecoder(strings.NewReader(`{
+		"versions": ["1.21.3", "1.21.4"],
+		"builds": [{"id": 7, "channel": "default"}, {"id": 8, "channel": "experimental"}],
+		"latest": {"build": 8, "stable": true},
+		"nested": [[1, 2], [3]]
+	}`))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{"versions", []string{"1.21.3", "1.21.4"}, false},
+		{"$.versions[0]", []string{"1.21.3"}, false},
+		{"versions[-1]", []string{"1.21.4"}, false},
+		{"builds[*].id", []string{"7", "8"}, false},
+		{"builds[-1].channel", []string{"experimental"}, false},
+		{"latest.build", []string{"8"}, false},
+		{"latest.stable", []string{"true"}, false},
+		{"nested[*][0]", []string{"1", "3"}, false},
+		{"nested[0]", []string{"1", "2"}, false},
+		{"latest", nil, true},
+		{"missing", nil, true},
+		{"versions[2]", nil, true},
+		{"versions.id", nil, true},
+		{"versions[x]", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := selectJSON(doc, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManifestValidate(t *testing.T) {
+	valid := func() Manifest {
+		return Manifest{
+			Name:     "fork",
+			Versions: ManifestEndpoint{URL: "https://example.com/versions", Path: "versions"},
+			Builds:   &ManifestEndpoint{URL: "https://example.com/{version}", Path: "builds"},
+			Download: ManifestEndpoint{URL: "https://example.com/{version}/{build}.jar"},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(m *Manifest)
+		wantErr bool
+	}{
+		{"valid", func(m *Manifest) {}, false},
+		{"invalid name", func(m *Manifest) { m.Name = "Fork Server" }, true},
+		{"no versions path", func(m *Manifest) { m.Versions.Path = "" }, true},
+		{"no download", func(m *Manifest) { m.Download.URL = "" }, true},
+		{"build without builds", func(m *Manifest) { m.Builds = nil }, true},
+		{"checksum file", func(m *Manifest) {
+			m.Checksum = &ManifestChecksum{ManifestEndpoint{URL: "https://example.com/{version}/{build}.jar.sha1"}, "sha1"}
+		}, false},
+		{"unsupported algorithm", func(m *Manifest) {
+			m.Checksum = &ManifestChecksum{ManifestEndpoint{URL: "https://example.com/sum"}, "crc32"}
+		}, true},
+		{"checksum from plain download", func(m *Manifest) {
+			m.Checksum = &ManifestChecksum{ManifestEndpoint{Path: "sha256"}, "sha256"}
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := valid()
+			tt.modify(&m)
+			if err := m.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestManifestProvider(t *testing.T) {
+	server := newManifestTestServer(t)
+	api := server.URL + "/v2/projects/fork"
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		download ManifestEndpoint
+		checksum *ManifestChecksum
+	}{
+		{
+			name:     "selected download and checksum",
+			download: ManifestEndpoint{URL: api + "/versions/{version}/builds/{build}", Path: "downloads.application.url"},
+			checksum: &ManifestChecksum{ManifestEndpoint{Path: "downloads.application.sha256"}, "sha256"},
+		},
+		{
+			name:     "templated download and checksum file",
+			download: ManifestEndpoint{URL: server.URL + "/jars/fork-{version}-{build}.jar"},
+			checksum: &ManifestChecksum{ManifestEndpoint{URL: server.URL + "/jars/fork-{version}-{build}.jar.sha256"}, "sha256"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestManifestProvider(t, Manifest{
+				Name:     "fork",
+				Versions: ManifestEndpoint{URL: api, Path: "versions"},
+				Builds:   &ManifestEndpoint{URL: api + "/versions/{version}", Path: "builds"},
+				Download: tt.download,
+				Checksum: tt.checksum,
+			})
+
+			versions, err := p.GetAvailableVersions(ctx)
+			if err != nil {
+				t.Fatalf("GetAvailableVersions() error = %v", err)
+			}
+			if want := []string{"1.21.3", "1.21.4"}; !reflect.DeepEqual(versions, want) {
+				t.Errorf("GetAvailableVersions() = %v, want %v", versions, want)
+			}
+
+			build, err := p.GetLatestBuild(ctx, "1.21.4")
+			if err != nil || build != "8" {
+				t.Errorf("GetLatestBuild() = %q, %v, want 8", build, err)
+			}
+
+			localPath, downloadURL, checksum, err := p.DownloadJar(ctx, "1.21.4", "latest")
+			if err != nil {
+				t.Fatalf("DownloadJar() error = %v", err)
+			}
+			// Selected URLs are resolved against the endpoint
+			if want := server.URL + "/jars/fork-1.21.4-8.jar"; downloadURL != want {
+				t.Errorf("DownloadJar() URL = %s, want %s", downloadURL, want)
+			}
+			sum := sha256.Sum256([]byte(manifestTestJar))
+			if checksum != hex.EncodeToString(sum[:]) {
+				t.Errorf("DownloadJar() checksum = %s", checksum)
+			}
+			if data, _ := os.ReadFile(localPath); string(data) != manifestTestJar {
+				t.Errorf("downloaded jar = %q", data)
+			}
+			if !p.cache.HasJar("fork", "1.21.4", "8") {
+				t.Error("DownloadJar() should cache the jar under the provider name")
+			}
+		})
+	}
+}
+
+func TestLoadManifests(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("test-fork.json", `{
+		"versions": {"url": "https://example.com/versions", "path": "versions"},
+		"download": {"url": "https://example.com/{version}.jar"}
+	}`)
+	write("paper.json", `{
+		"versions": {"url": "https://example.com/versions", "path": "versions"},
+		"download": {"url": "https://example.com/{version}.jar"}
+	}`)
+	write("broken.json", `{"versions": {}}`)
+	write("notes.txt", `not a manifest`)
+	t.Cleanup(func() { delete(providers, "test-fork") })
+
+	loaded, err := LoadManifests(dir)
+	if !reflect.DeepEqual(loaded, []string{"test-fork"}) {
+		t.Errorf("LoadManifests() = %v, want [test-fork]", loaded)
+	}
+	if err == nil || !strings.Contains(err.Error(), "broken.json") || !strings.Contains(err.Error(), "already registered") {
+		t.Errorf("LoadManifests() error = %v, want the broken and duplicate manifests reported", err)
+	}
+
+	p, err := Get("test-fork")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if p.GetName() != "test-fork" {
+		t.Errorf("GetName() = %s, want test-fork", p.GetName())
+	}
+	if _, ok := p.(*ManifestProvider); !ok {
+		t.Errorf("Get() = %T, want *ManifestProvider", p)
+	}
+
+	if loaded, err := LoadManifests(filepath.Join(dir, "missing")); err != nil || len(loaded) != 0 {
+		t.Errorf("LoadManifests() of a missing directory = %v, %v", loaded, err)
+	}
+}
@@ -0,0 +1,34 @@
+package provider
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+)
+
+// MavenMetadata is the subset of a maven-metadata.xml file listing versions
+type MavenMetadata struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Versioning struct {
+		Latest   string   `xml:"latest"`
+		Release  string   `xml:"release"`
+		Versions []string `xml:"versions>version"`
+	} `xml:"versioning"`
+}
+
+// fetchMavenMetadata fetches and parses a maven-metadata.xml file
+func fetchMavenMetadata(ctx context.Context, client *http.Client, url string) (*MavenMetadata, error) {
+	body, err := fetchBytes(ctx, client, url)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata MavenMetadata
+	if err := xml.Unmarshal(body, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse maven metadata from %s: %w", url, err)
+	}
+
+	return &metadata, nil
+}
@@ -0,0 +1,225 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackh54/mcinit/internal/cache"
+)
+
+// Mirror routes requests for an upstream base URL to the base URLs
+// configured under Name, usually the provider name. Without configuration
+// requests go to the upstream.
+type Mirror struct {
+	Name     string
+	Upstream string
+}
+
+var (
+	mirrorsMu sync.RWMutex
+	mirrors   = map[string][]string{}
+)
+
+// SetMirrors sets the base URLs tried in order for the mirror name,
+// replacing its upstream. An empty list restores the upstream.
+func SetMirrors(name string, baseURLs []string) {
+	mirrorsMu.Lock()
+	defer mirrorsMu.Unlock()
+
+	if len(baseURLs) == 0 {
+		delete(mirrors, name)
+		return
+	}
+	mirrors[name] = baseURLs
+}
+
+// MirrorsFile returns the path of the mirror configuration,
+// mcinit/mirrors.json in the user's configuration directory
+func MirrorsFile() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "mcinit", "mirrors.json"), nil
+}
+
+// LoadMirrors reads a mirror configuration mapping mirror names to base
+// URLs and applies it with SetMirrors. A missing file is not an error.
+func LoadMirrors(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var config map[string][]string
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for name, baseURLs := range config {
+		for _, baseURL := range baseURLs {
+			if err := validateBaseURL(baseURL); err != nil {
+				return fmt.Errorf("%s: %s: %w", path, name, err)
+			}
+		}
+		SetMirrors(name, baseURLs)
+	}
+	return nil
+}
+
+// MirrorEnv returns the environment variable that overrides the base URLs
+// of a mirror name, e.g. MCINIT_PAPER_URL or MCINIT_VANILLA_DATA_URL. It
+// holds a comma-separated list.
+func MirrorEnv(name string) string {
+	return "MCINIT_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_URL"
+}
+
+// BaseURLs returns the base URLs to try in order for the mirror: those of
+// its environment variable, else the configured ones, else the upstream.
+// Base URLs that are not http(s) URLs are an error, wherever they are from.
+func (m Mirror) BaseURLs() ([]string, error) {
+	var baseURLs []string
+	source := "mirror " + m.Name
+	if env := os.Getenv(MirrorEnv(m.Name)); env != "" {
+		baseURLs = strings.Split(env, ",")
+		source = MirrorEnv(m.Name)
+	} else {
+		mirrorsMu.RLock()
+		baseURLs = mirrors[m.Name]
+		mirrorsMu.RUnlock()
+	}
+
+	cleaned := make([]string, 0, len(baseURLs))
+	for _, baseURL := range baseURLs {
+		baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+		if baseURL == "" {
+			continue
+		}
+		if err := validateBaseURL(baseURL); err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+		cleaned = append(cleaned, baseURL)
+	}
+	if len(cleaned) == 0 {
+		return []string{m.Upstream}, nil
+	}
+	return cleaned, nil
+}
+
+// relative returns the part of rawURL after the upstream, if it is under it
+func (m Mirror) relative(rawURL string) (string, bool) {
+	rest := strings.TrimPrefix(rawURL, m.Upstream)
+	if rest == rawURL {
+		return "", false
+	}
+	if rest != "" && rest[0] != '/' && rest[0] != '?' {
+		return "", false
+	}
+	return rest, true
+}
+
+// validateBaseURL checks that a configured base URL is an absolute http(s) URL
+func validateBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid base URL %q: must be an http or https URL", baseURL)
+	}
+	return nil
+}
+
+// newHTTPClient returns the API client of a provider, which sends requests
+// for the given upstreams to their mirrors
+func newHTTPClient(mirrors ...Mirror) *http.Client {
+	return &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &mirrorTransport{
+			base:    http.DefaultTransport,
+			mirrors: mirrors,
+		},
+	}
+}
+
+// newDownloader returns a jar downloader that uses the mirrors of a
+// provider's client
+func newDownloader(c *cache.Cache, client *http.Client) *cache.Downloader {
+	return cache.NewDownloader(c).WithTransport(client.Transport)
+}
+
+// mirrorTransport rewrites GET requests under a mirrored upstream to each of
+// its base URLs in turn, until one does not fail with a network error, a
+// 404 or a server error. URLs keep pointing at the upstream everywhere
+// else, so recorded download URLs do not depend on the mirror used.
+type mirrorTransport struct {
+	base    http.RoundTripper
+	mirrors []Mirror
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *mirrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.base.RoundTrip(req)
+	}
+
+	for _, mirror := range t.mirrors {
+		rest, ok := mirror.relative(req.URL.String())
+		if !ok {
+			continue
+		}
+
+		baseURLs, err := mirror.BaseURLs()
+		if err != nil {
+			return nil, err
+		}
+		if len(baseURLs) == 1 && baseURLs[0] == mirror.Upstream {
+			break
+		}
+		return t.failover(req, baseURLs, rest)
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// failover sends req to each base URL with the same relative URL
+func (t *mirrorTransport) failover(req *http.Request, baseURLs []string, rest string) (*http.Response, error) {
+	var errs []string
+	for i, baseURL := range baseURLs {
+		target, err := url.Parse(baseURL + rest)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", baseURL, err))
+			continue
+		}
+
+		mirrored := req.Clone(req.Context())
+		mirrored.URL = target
+		mirrored.Host = target.Host
+
+		resp, err := t.base.RoundTrip(mirrored)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		last := i == len(baseURLs)-1
+		if last || (resp.StatusCode != http.StatusNotFound && resp.StatusCode < 500) {
+			return resp, nil
+		}
+
+		errs = append(errs, fmt.Sprintf("%s: %s", target, resp.Status))
+		_ = resp.Body.Close()
+	}
+
+	return nil, fmt.Errorf("all mirrors failed: %s", strings.Join(errs, "; "))
+}
@@ -0,0 +1,212 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMirrorEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"paper", "MCINIT_PAPER_URL"},
+		{"vanilla-data", "MCINIT_VANILLA_DATA_URL"},
+		{"spongeforge-repo", "MCINIT_SPONGEFORGE_REPO_URL"},
+	}
+
+	for _, tt := range tests {
+		if got := MirrorEnv(tt.name); got != tt.want {
+			t.Errorf("MirrorEnv(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMirrorBaseURLs(t *testing.T) {
+	mirror := Mirror{"test-mirror", "https://upstream.example.com"}
+	t.Cleanup(func() { SetMirrors("test-mirror", nil) })
+
+	if got, err := mirror.BaseURLs(); err != nil || !reflect.DeepEqual(got, []string{"https://upstream.example.com"}) {
+		t.Errorf("BaseURLs() without configuration = %v, %v, want the upstream", got, err)
+	}
+
+	SetMirrors("test-mirror", []string{"https://proxy.example.com/upstream/", "https://upstream.example.com"})
+	want := []string{"https://proxy.example.com/upstream", "https://upstream.example.com"}
+	if got, err := mirror.BaseURLs(); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("BaseURLs() with configuration = %v, %v, want %v", got, err, want)
+	}
+
+	// The environment takes precedence over the configuration
+	t.Setenv("MCINIT_TEST_MIRROR_URL", "https://a.example.com, https://b.example.com/")
+	want = []string{"https://a.example.com", "https://b.example.com"}
+	if got, err := mirror.BaseURLs(); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("BaseURLs() with environment = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestMirrorBaseURLsInvalid(t *testing.T) {
+	mirror := Mirror{"test-mirror", "https://upstream.example.com"}
+	t.Cleanup(func() { SetMirrors("test-mirror", nil) })
+
+	SetMirrors("test-mirror", []string{"proxy.example.com"})
+	if _, err := mirror.BaseURLs(); err == nil || !strings.Contains(err.Error(), "mirror test-mirror: invalid base URL") {
+		t.Errorf("BaseURLs() with configuration error = %v, want an invalid base URL", err)
+	}
+
+	t.Setenv("MCINIT_TEST_MIRROR_URL", "https://a.example.com,ftp://b.example.com")
+	if _, err := mirror.BaseURLs(); err == nil || !strings.Contains(err.Error(), "MCINIT_TEST_MIRROR_URL: invalid base URL") {
+		t.Errorf("BaseURLs() with environment error = %v, want an invalid base URL", err)
+	}
+
+	// Requests fail instead of going to the upstream
+	client := newHTTPClient(mirror)
+	if _, err := client.Get("https://upstream.example.com/v2"); err == nil || !strings.Contains(err.Error(), "MCINIT_TEST_MIRROR_URL") {
+		t.Errorf("Get() error = %v, want the invalid base URL", err)
+	}
+}
+
+func TestMirrorRelative(t *testing.T) {
+	mirror := Mirror{"test-mirror", "https://upstream.example.com/api"}
+
+	tests := []struct {
+		url    string
+		want   string
+		wantOK bool
+	}{
+		{"https://upstream.example.com/api", "", true},
+		{"https://upstream.example.com/api/v2/projects", "/v2/projects", true},
+		{"https://upstream.example.com/api?limit=1", "?limit=1", true},
+		{"https://upstream.example.com/apiv2", "", false},
+		{"https://other.example.com/api/v2", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := mirror.relative(tt.url)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("relative(%q) = %q, %v, want %q, %v", tt.url, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestMirrorFailover(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "proxy error", http.StatusBadGateway)
+	}))
+	t.Cleanup(failing.Close)
+
+	missing := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(missing.Close)
+
+	working := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "served %s", r.URL.RequestURI())
+	}))
+	t.Cleanup(working.Close)
+
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	client := newHTTPClient(Mirror{"test-mirror", "https://upstream.example.com/api"})
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		baseURLs   string
+		url        string
+		want       string
+		wantStatus int
+		wantErr    bool
+	}{
+		{
+			name:       "fails over to the first working mirror",
+			baseURLs:   strings.Join([]string{unreachable.URL, failing.URL, missing.URL, working.URL + "/mirror"}, ","),
+			url:        "https://upstream.example.com/api/v2/projects?limit=1",
+			want:       "served /mirror/v2/projects?limit=1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "returns the last response if every mirror fails",
+			baseURLs:   failing.URL + "," + missing.URL,
+			url:        "https://upstream.example.com/api/v2/projects",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:     "reports the errors if no mirror responds",
+			baseURLs: unreachable.URL,
+			url:      "https://upstream.example.com/api/v2/projects",
+			wantErr:  true,
+		},
+		{
+			name:       "other URLs are not rewritten",
+			baseURLs:   unreachable.URL,
+			url:        working.URL + "/api/v2",
+			want:       "served /api/v2",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MCINIT_TEST_MIRROR_URL", tt.baseURLs)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "all mirrors failed") {
+					t.Errorf("Do() error = %v, want all mirrors failed", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.want != "" && string(body) != tt.want {
+				t.Errorf("body = %q, want %q", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMirrors(t *testing.T) {
+	dir := t.TempDir()
+	t.Cleanup(func() { SetMirrors("test-mirror", nil) })
+
+	if err := LoadMirrors(filepath.Join(dir, "missing.json")); err != nil {
+		t.Errorf("LoadMirrors() of a missing file error = %v", err)
+	}
+
+	path := filepath.Join(dir, "mirrors.json")
+	if err := os.WriteFile(path, []byte(`{"test-mirror": ["https://proxy.example.com/paper"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadMirrors(path); err != nil {
+		t.Fatalf("LoadMirrors() error = %v", err)
+	}
+	mirror := Mirror{"test-mirror", "https://upstream.example.com"}
+	if got, err := mirror.BaseURLs(); err != nil || !reflect.DeepEqual(got, []string{"https://proxy.example.com/paper"}) {
+		t.Errorf("BaseURLs() = %v, %v, want the configured mirror", got, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"test-mirror": ["proxy.example.com"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadMirrors(path); err == nil {
+		t.Error("LoadMirrors() should reject base URLs without a scheme")
+	}
+}
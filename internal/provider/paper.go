@@ -6,16 +6,51 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/jackh54/mcinit/internal/cache"
 )
 
+const (
+	// paperAPIURL is the base URL of the PaperMC downloads API (v2)
+	paperAPIURL = "https://api.papermc.io"
+
+	// fillAPIURL is the base URL of PaperMC's Fill API (v3), which replaces
+	// the downloads API, and fillDataURL the host of its jars
+	fillAPIURL  = "https://fill.papermc.io"
+	fillDataURL = "https://fill-data.papermc.io"
+)
+
+// PaperMC APIs a PaperProvider can prefer
+const (
+	PaperAPIV2   = "v2"
+	PaperAPIFill = "v3"
+)
+
+// PaperAPIEnv returns the environment variable that selects the API of a
+// PaperMC project, e.g. MCINIT_PAPER_API. It holds PaperAPIV2 or PaperAPIFill
+// and overrides the project's default.
+func PaperAPIEnv(project string) string {
+	return "MCINIT_" + strings.ToUpper(strings.ReplaceAll(project, "-", "_")) + "_API"
+}
+
+// fillServerDownload is the download kind of the server jar in the Fill API
+const fillServerDownload = "server:default"
+
+// PaperMC build channels, from stable to least stable
+const (
+	PaperChannelDefault      = "default"
+	PaperChannelExperimental = "experimental"
+)
+
 // PaperProvider implements Provider for PaperMC
 type PaperProvider struct {
 	cache       *cache.Cache
 	client      *http.Client
 	projectName string
+	api         string // default API, PaperAPIFill falls back to v2 when the Fill API fails
 }
 
 // NewPaperProvider creates a new PaperProvider
@@ -24,84 +59,141 @@ func NewPaperProvider() *PaperProvider {
 	return &PaperProvider{
 		cache:       c,
 		projectName: "paper",
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		api:         PaperAPIFill,
+		client:      newPaperClient("paper"),
 	}
 }
 
+// newPaperClient returns the client of a PaperMC project, whose mirrors are
+// named after the project: e.g. paper for the v2 API, paper-fill for the
+// Fill API and paper-fill-data for its jars
+func newPaperClient(project string) *http.Client {
+	return newHTTPClient(
+		Mirror{project, paperAPIURL},
+		Mirror{project + "-fill", fillAPIURL},
+		Mirror{project + "-fill-data", fillDataURL},
+	)
+}
+
 // GetName returns the provider name
 func (p *PaperProvider) GetName() string {
 	return p.projectName
 }
 
-// GetAvailableVersions returns all available Minecraft versions
+// GetAvailableVersions returns all available Minecraft versions, oldest first
 func (p *PaperProvider) GetAvailableVersions(ctx context.Context) ([]string, error) {
-	url := fmt.Sprintf("https://api.papermc.io/v2/projects/%s", p.projectName)
+	var versions []string
+	err := p.withFallback(func() (err error) {
+		versions, err = p.fillVersions(ctx)
+		return err
+	}, func() (err error) {
+		versions, err = p.v2Versions(ctx)
+		return err
+	})
+	return versions, err
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
+// GetLatestBuild returns the latest build for a version in the default
+// channel
+func (p *PaperProvider) GetLatestBuild(ctx context.Context, version string) (string, error) {
+	build, _, err := p.ResolveChannelBuild(ctx, version, PaperChannelDefault, "latest")
+	return build, err
+}
 
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return nil, err
+// BuildChannels returns the PaperMC build channels, from stable to least
+// stable
+func (p *PaperProvider) BuildChannels() []string {
+	return []string{PaperChannelDefault, PaperChannelExperimental}
+}
+
+// ResolveChannelBuild resolves "latest" to the newest build in channel or a
+// more stable one, and returns the build with its channel. Other builds are
+// returned as they are, whatever their channel.
+func (p *PaperProvider) ResolveChannelBuild(ctx context.Context, version, channel, build string) (string, string, error) {
+	allowed := paperChannelRank(channel)
+	if allowed < 0 {
+		return "", "", fmt.Errorf("invalid build channel %q (available: %s, %s)", channel, PaperChannelDefault, PaperChannelExperimental)
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	if build != "" && build != "latest" {
+		buildInfo, err := p.getBuildInfo(ctx, version, build)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get build info: %w", err)
+		}
+		return build, buildInfo.Channel, nil
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	builds, err := p.getBuilds(ctx, version)
 	if err != nil {
-		return nil, err
+		return "", "", err
 	}
-
-	var project PaperProject
-	if err := json.Unmarshal(body, &project); err != nil {
-		return nil, err
+	if len(builds) == 0 {
+		return "", "", fmt.Errorf("no builds available for version %s", version)
 	}
 
-	return project.Versions, nil
+	// Builds are listed oldest first
+	for i := len(builds) - 1; i >= 0; i-- {
+		if rank := paperChannelRank(builds[i].Channel); rank >= 0 && rank <= allowed {
+			return fmt.Sprintf("%d", builds[i].Build), builds[i].Channel, nil
+		}
+	}
+	return "", "", fmt.Errorf("no %s builds available for version %s, only %s ones",
+		channel, version, builds[len(builds)-1].Channel)
 }
 
-// GetLatestBuild returns the latest build for a version
-func (p *PaperProvider) GetLatestBuild(ctx context.Context, version string) (string, error) {
-	url := fmt.Sprintf("https://api.papermc.io/v2/projects/%s/versions/%s", p.projectName, version)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return "", err
+// RequiredJava returns the minimum Java major version of a Minecraft version
+// from the Fill API's metadata, or 0 if the provider uses the v2 API, which
+// does not publish it
+func (p *PaperProvider) RequiredJava(ctx context.Context, version string) (int, error) {
+	api, err := p.preferredAPI()
+	if err != nil || api != PaperAPIFill {
+		return 0, err
 	}
 
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer func() { _ = resp.Body.Close() }()
+	url := fmt.Sprintf("%s/v3/projects/%s/versions/%s", fillAPIURL, p.projectName, version)
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	var versionInfo FillVersion
+	if err := p.getJSON(ctx, url, &versionInfo); err != nil {
+		return 0, err
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	return versionInfo.Version.Java.Version.Minimum, nil
+}
+
+// Changes returns the builds of a version with their commits, newest first
+func (p *PaperProvider) Changes(ctx context.Context, version string) ([]BuildChanges, error) {
+	builds, err := p.getBuilds(ctx, version)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	var versionInfo PaperVersion
-	if err := json.Unmarshal(body, &versionInfo); err != nil {
-		return "", err
+	changes := make([]BuildChanges, len(builds))
+	for i, b := range builds {
+		c := BuildChanges{Build: fmt.Sprintf("%d", b.Build), Channel: b.Channel}
+		c.Time, _ = time.Parse(time.RFC3339, b.Time)
+		for _, change := range b.Changes {
+			summary := change.Summary
+			if summary == "" {
+				summary = commitSummary(change.Message)
+			}
+			c.Commits = append(c.Commits, Commit{Hash: change.Commit, Summary: summary})
+		}
+		changes[len(changes)-1-i] = c
 	}
+	return changes, nil
+}
 
-	if len(versionInfo.Builds) == 0 {
-		return "", fmt.Errorf("no builds available for version %s", version)
+// paperChannelRank returns the stability rank of a build channel, lower is
+// more stable, or -1 for unknown channels
+func paperChannelRank(channel string) int {
+	switch channel {
+	case PaperChannelDefault:
+		return 0
+	case PaperChannelExperimental:
+		return 1
+	default:
+		return -1
 	}
-
-	// Return the latest build (last in the array)
-	return fmt.Sprintf("%d", versionInfo.Builds[len(versionInfo.Builds)-1]), nil
 }
 
 // DownloadJar downloads the Paper server jar
@@ -121,13 +213,15 @@ func (p *PaperProvider) DownloadJar(ctx context.Context, version, build string)
 		return "", "", "", fmt.Errorf("failed to get build info: %w", err)
 	}
 
-	downloadURL := fmt.Sprintf("https://api.papermc.io/v2/projects/%s/versions/%s/builds/%s/downloads/%s",
-		p.projectName, version, build, buildInfo.Downloads.Application.Name)
+	downloadURL, err := p.jarURL(version, buildInfo)
+	if err != nil {
+		return "", "", "", err
+	}
 
 	checksum := buildInfo.Downloads.Application.SHA256
 
 	// Download using cache/downloader
-	downloader := cache.NewDownloader(p.cache)
+	downloader := newDownloader(p.cache, p.client)
 	localPath, err := downloader.DownloadJar(downloadURL, p.projectName, version, build, checksum, "sha256")
 	if err != nil {
 		return "", "", "", fmt.Errorf("failed to download jar: %w", err)
@@ -151,8 +245,7 @@ func (p *PaperProvider) GetDownloadURL(ctx context.Context, version, build strin
 		return "", err
 	}
 
-	return fmt.Sprintf("https://api.papermc.io/v2/projects/%s/versions/%s/builds/%s/downloads/%s",
-		p.projectName, version, build, buildInfo.Downloads.Application.Name), nil
+	return p.jarURL(version, buildInfo)
 }
 
 // GetChecksum returns the checksum
@@ -173,37 +266,159 @@ func (p *PaperProvider) GetChecksum(ctx context.Context, version, build string)
 	return "sha256", buildInfo.Downloads.Application.SHA256, nil
 }
 
+// jarURL returns the download URL of a build's server jar. The Fill API
+// names it; v2 URLs are derived from the jar name.
+func (p *PaperProvider) jarURL(version string, buildInfo *PaperBuild) (string, error) {
+	if buildInfo.url != "" {
+		return buildInfo.url, nil
+	}
+	if buildInfo.Downloads.Application.Name == "" {
+		return "", fmt.Errorf("build %d of %s %s has no server download", buildInfo.Build, p.projectName, version)
+	}
+	return fmt.Sprintf("%s/v2/projects/%s/versions/%s/builds/%d/downloads/%s",
+		paperAPIURL, p.projectName, version, buildInfo.Build, buildInfo.Downloads.Application.Name), nil
+}
+
+// getBuilds fetches all builds of a version, oldest first
+func (p *PaperProvider) getBuilds(ctx context.Context, version string) ([]PaperBuild, error) {
+	var builds []PaperBuild
+	err := p.withFallback(func() error {
+		url := fmt.Sprintf("%s/v3/projects/%s/versions/%s/builds", fillAPIURL, p.projectName, version)
+
+		var fillBuilds []FillBuild
+		if err := p.getJSON(ctx, url, &fillBuilds); err != nil {
+			return err
+		}
+
+		// The Fill API lists builds newest first
+		builds = make([]PaperBuild, len(fillBuilds))
+		for i, b := range fillBuilds {
+			builds[len(builds)-1-i] = b.paperBuild(p.projectName, version)
+		}
+		return nil
+	}, func() error {
+		url := fmt.Sprintf("%s/v2/projects/%s/versions/%s/builds", paperAPIURL, p.projectName, version)
+
+		var v2Builds PaperBuilds
+		if err := p.getJSON(ctx, url, &v2Builds); err != nil {
+			return err
+		}
+		builds = v2Builds.Builds
+		return nil
+	})
+	return builds, err
+}
+
 // getBuildInfo fetches build information from the API
 func (p *PaperProvider) getBuildInfo(ctx context.Context, version, build string) (*PaperBuild, error) {
-	url := fmt.Sprintf("https://api.papermc.io/v2/projects/%s/versions/%s/builds/%s",
-		p.projectName, version, build)
+	var buildInfo PaperBuild
+	err := p.withFallback(func() error {
+		url := fmt.Sprintf("%s/v3/projects/%s/versions/%s/builds/%s", fillAPIURL, p.projectName, version, build)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		var fillBuild FillBuild
+		if err := p.getJSON(ctx, url, &fillBuild); err != nil {
+			return err
+		}
+		buildInfo = fillBuild.paperBuild(p.projectName, version)
+		return nil
+	}, func() error {
+		url := fmt.Sprintf("%s/v2/projects/%s/versions/%s/builds/%s", paperAPIURL, p.projectName, version, build)
+		return p.getJSON(ctx, url, &buildInfo)
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	return &buildInfo, nil
+}
+
+// fillVersions fetches the versions of the project from the Fill API
+func (p *PaperProvider) fillVersions(ctx context.Context) ([]string, error) {
+	url := fmt.Sprintf("%s/v3/projects/%s/versions", fillAPIURL, p.projectName)
+
+	var project FillVersions
+	if err := p.getJSON(ctx, url, &project); err != nil {
+		return nil, err
+	}
+
+	// The Fill API lists versions newest first
+	versions := make([]string, len(project.Versions))
+	for i, v := range project.Versions {
+		versions[len(versions)-1-i] = v.Version.ID
+	}
+	return versions, nil
+}
+
+// v2Versions fetches the versions of the project from the v2 API
+func (p *PaperProvider) v2Versions(ctx context.Context) ([]string, error) {
+	url := fmt.Sprintf("%s/v2/projects/%s", paperAPIURL, p.projectName)
+
+	var project PaperProject
+	if err := p.getJSON(ctx, url, &project); err != nil {
+		return nil, err
+	}
+
+	return project.Versions, nil
+}
+
+// withFallback runs fill if the provider prefers the Fill API and v2 if it
+// does not or fill fails
+func (p *PaperProvider) withFallback(fill, v2 func() error) error {
+	api, err := p.preferredAPI()
+	if err != nil {
+		return err
+	}
+	if api != PaperAPIFill {
+		return v2()
+	}
+
+	fillErr := fill()
+	if fillErr == nil {
+		return nil
+	}
+	if err := v2(); err != nil {
+		return fmt.Errorf("%w (v2 API: %v)", fillErr, err)
+	}
+	return nil
+}
+
+// preferredAPI returns the API selected by the project's environment
+// variable, or else the provider's default
+func (p *PaperProvider) preferredAPI() (string, error) {
+	env := PaperAPIEnv(p.projectName)
+	switch api := os.Getenv(env); api {
+	case "":
+		return p.api, nil
+	case PaperAPIV2, PaperAPIFill:
+		return api, nil
+	default:
+		return "", fmt.Errorf("invalid %s %q: must be %s or %s", env, api, PaperAPIV2, PaperAPIFill)
+	}
+}
+
+// getJSON fetches url and decodes the JSON response into v
+func (p *PaperProvider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var buildInfo PaperBuild
-	if err := json.Unmarshal(body, &buildInfo); err != nil {
-		return nil, err
-	}
-
-	return &buildInfo, nil
+	return json.Unmarshal(body, v)
 }
 
 // PaperMC API structures
@@ -221,17 +436,116 @@ type PaperVersion struct {
 	Builds      []int  `json:"builds"`
 }
 
+type PaperBuilds struct {
+	ProjectID   string       `json:"project_id"`
+	ProjectName string       `json:"project_name"`
+	Version     string       `json:"version"`
+	Builds      []PaperBuild `json:"builds"`
+}
+
 type PaperBuild struct {
-	ProjectID   string `json:"project_id"`
-	ProjectName string `json:"project_name"`
-	Version     string `json:"version"`
-	Build       int    `json:"build"`
-	Time        string `json:"time"`
-	Channel     string `json:"channel"`
+	ProjectID   string        `json:"project_id"`
+	ProjectName string        `json:"project_name"`
+	Version     string        `json:"version"`
+	Build       int           `json:"build"`
+	Time        string        `json:"time"`
+	Channel     string        `json:"channel"`
+	Changes     []PaperChange `json:"changes"`
 	Downloads   struct {
 		Application struct {
 			Name   string `json:"name"`
 			SHA256 string `json:"sha256"`
 		} `json:"application"`
 	} `json:"downloads"`
+
+	url string // download URL of the server jar, named by the Fill API
+}
+
+type PaperChange struct {
+	Commit  string `json:"commit"`
+	Summary string `json:"summary"`
+	Message string `json:"message"`
+}
+
+// Fill API structures
+
+type FillVersions struct {
+	Versions []FillVersion `json:"versions"`
+}
+
+type FillVersion struct {
+	Version struct {
+		ID      string `json:"id"`
+		Support struct {
+			Status string `json:"status"`
+		} `json:"support"`
+		Java struct {
+			Version struct {
+				Minimum int `json:"minimum"`
+			} `json:"version"`
+			Flags struct {
+				Recommended []string `json:"recommended"`
+			} `json:"flags"`
+		} `json:"java"`
+	} `json:"version"`
+	Builds []int `json:"builds"`
+}
+
+type FillBuild struct {
+	ID        int                     `json:"id"`
+	Time      string                  `json:"time"`
+	Channel   string                  `json:"channel"`
+	Commits   []FillCommit            `json:"commits"`
+	Downloads map[string]FillDownload `json:"downloads"`
+}
+
+type FillCommit struct {
+	SHA     string `json:"sha"`
+	Time    string `json:"time"`
+	Message string `json:"message"`
+}
+
+type FillDownload struct {
+	Name      string `json:"name"`
+	Checksums struct {
+		SHA256 string `json:"sha256"`
+	} `json:"checksums"`
+	Size int64  `json:"size"`
+	URL  string `json:"url"`
+}
+
+// paperBuild converts a Fill build to the v2 structure, mapping its channel
+// to the v2 channels: STABLE and RECOMMENDED builds are default ones, ALPHA
+// and BETA builds experimental ones
+func (b FillBuild) paperBuild(project, version string) PaperBuild {
+	build := PaperBuild{
+		ProjectID: project,
+		Version:   version,
+		Build:     b.ID,
+		Time:      b.Time,
+	}
+
+	for _, commit := range b.Commits {
+		build.Changes = append(build.Changes, PaperChange{
+			Commit:  commit.SHA,
+			Summary: commitSummary(commit.Message),
+			Message: commit.Message,
+		})
+	}
+
+	switch b.Channel {
+	case "STABLE", "RECOMMENDED":
+		build.Channel = PaperChannelDefault
+	case "ALPHA", "BETA":
+		build.Channel = PaperChannelExperimental
+	default:
+		build.Channel = strings.ToLower(b.Channel)
+	}
+
+	if download, ok := b.Downloads[fillServerDownload]; ok {
+		build.Downloads.Application.Name = download.Name
+		build.Downloads.Application.SHA256 = download.Checksums.SHA256
+		build.url = download.URL
+	}
+	return build
 }
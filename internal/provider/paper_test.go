@@ -0,0 +1,317 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const paperTestJar = "paper server jar"
+
+// newPaperTestServer serves the PaperMC API for builds 100 and 101 of 1.21.4,
+// and the experimental build 102
+func newPaperTestServer(t *testing.T, project string) *httptest.Server {
+	t.Helper()
+
+	sum := sha256.Sum256([]byte(paperTestJar))
+	base := "/v2/projects/" + project
+	mux := http.NewServeMux()
+	mux.HandleFunc(base, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"project_id":%q,"versions":["1.21.3","1.21.4"]}`, project)
+	})
+	mux.HandleFunc(base+"/versions/1.21.4", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"version":"1.21.4","builds":[100,101,102]}`)
+	})
+	mux.HandleFunc(base+"/versions/1.21.4/builds", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"version":"1.21.4","builds":[
+			{"build":100,"channel":"default"},
+			{"build":101,"time":"2025-01-05T10:00:00.123Z","channel":"default",
+				"changes":[{"commit":"abcdef0123456789","summary":"Fix chunk loading","message":"Fix chunk loading\n\nDetails"}]},
+			{"build":102,"channel":"experimental"}]}`)
+	})
+	mux.HandleFunc(base+"/versions/1.21.5/builds", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"version":"1.21.5","builds":[{"build":1,"channel":"experimental"}]}`)
+	})
+	mux.HandleFunc(base+"/versions/1.21.4/builds/101", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"build":101,"channel":"default","downloads":{"application":{"name":"%s-1.21.4-101.jar","sha256":%q}}}`,
+			project, hex.EncodeToString(sum[:]))
+	})
+	mux.HandleFunc(base+"/versions/1.21.4/builds/101/downloads/"+project+"-1.21.4-101.jar", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, paperTestJar)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newFillTestServer serves the Fill API and its jars for the stable build
+// 101 of 1.21.4, and the beta build 102
+func newFillTestServer(t *testing.T, project string) *httptest.Server {
+	t.Helper()
+
+	checksum := sha256.Sum256([]byte(paperTestJar))
+	sum := hex.EncodeToString(checksum[:])
+	build101 := fmt.Sprintf(`{"id":101,"time":"2025-01-05T10:00:00Z","channel":"STABLE",
+		"commits":[{"sha":"abcdef0123456789","time":"2025-01-05T09:00:00Z","message":"Fix chunk loading\n\nDetails"}],"downloads":{
+		"server:default":{"name":"%[1]s-1.21.4-101.jar","checksums":{"sha256":%[2]q},"url":"https://fill-data.papermc.io/v1/objects/%[2]s/%[1]s-1.21.4-101.jar"},
+		"server:mojmap":{"name":"%[1]s-mojmap-1.21.4-101.jar","checksums":{"sha256":"0000"},"url":"https://fill-data.papermc.io/v1/objects/0000/%[1]s-mojmap-1.21.4-101.jar"}}}`,
+		project, sum)
+
+	base := "/v3/projects/" + project
+	mux := http.NewServeMux()
+	mux.HandleFunc(base+"/versions", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"versions":[
+			{"version":{"id":"1.21.5","java":{"version":{"minimum":21}}},"builds":[1]},
+			{"version":{"id":"1.21.4","java":{"version":{"minimum":21}}},"builds":[102,101]},
+			{"version":{"id":"1.16.5","java":{"version":{"minimum":8}}},"builds":[794]}]}`)
+	})
+	mux.HandleFunc(base+"/versions/1.16.5", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"version":{"id":"1.16.5","java":{"version":{"minimum":8},"flags":{"recommended":[]}}},"builds":[794]}`)
+	})
+	mux.HandleFunc(base+"/versions/1.21.4/builds", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"id":102,"time":"2025-01-06T10:00:00Z","channel":"BETA","downloads":{}},%s]`, build101)
+	})
+	mux.HandleFunc(base+"/versions/1.21.4/builds/101", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, build101)
+	})
+	mux.HandleFunc("/v1/objects/"+sum+"/"+project+"-1.21.4-101.jar", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, paperTestJar)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPaperProvider(t *testing.T) {
+	server := newPaperTestServer(t, "paper")
+	t.Setenv("MCINIT_PAPER_URL", server.URL)
+
+	// Without the Fill API the provider falls back to v2
+	retired := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(retired.Close)
+	t.Setenv("MCINIT_PAPER_FILL_URL", retired.URL)
+
+	p := NewPaperProvider()
+	p.cache = useTestCache(t)
+	ctx := context.Background()
+
+	versions, err := p.GetAvailableVersions(ctx)
+	if err != nil {
+		t.Fatalf("GetAvailableVersions() error = %v", err)
+	}
+	if want := []string{"1.21.3", "1.21.4"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("GetAvailableVersions() = %v, want %v", versions, want)
+	}
+
+	build, err := p.GetLatestBuild(ctx, "1.21.4")
+	if err != nil || build != "101" {
+		t.Errorf("GetLatestBuild() = %q, %v, want 101", build, err)
+	}
+
+	testPaperChanges(t, p)
+
+	localPath, downloadURL, checksum, err := p.DownloadJar(ctx, "1.21.4", "latest")
+	if err != nil {
+		t.Fatalf("DownloadJar() error = %v", err)
+	}
+
+	// The recorded URL is the upstream one, whichever mirror served the jar
+	if want := "https://api.papermc.io/v2/projects/paper/versions/1.21.4/builds/101/downloads/paper-1.21.4-101.jar"; downloadURL != want {
+		t.Errorf("DownloadJar() URL = %s, want %s", downloadURL, want)
+	}
+	sum := sha256.Sum256([]byte(paperTestJar))
+	if checksum != hex.EncodeToString(sum[:]) {
+		t.Errorf("DownloadJar() checksum = %s", checksum)
+	}
+	if data, _ := os.ReadFile(localPath); string(data) != paperTestJar {
+		t.Errorf("downloaded jar = %q", data)
+	}
+}
+
+func TestPaperResolveChannelBuild(t *testing.T) {
+	server := newPaperTestServer(t, "paper")
+	t.Setenv("MCINIT_PAPER_URL", server.URL)
+
+	tests := []struct {
+		name        string
+		version     string
+		channel     string
+		build       string
+		wantBuild   string
+		wantChannel string
+		wantErr     bool
+	}{
+		{"latest default build", "1.21.4", "default", "latest", "101", "default", false},
+		{"latest experimental build", "1.21.4", "experimental", "latest", "102", "experimental", false},
+		{"pinned build keeps its channel", "1.21.4", "experimental", "101", "101", "default", false},
+		{"only experimental builds", "1.21.5", "default", "latest", "", "", true},
+		{"experimental allowed", "1.21.5", "experimental", "", "1", "experimental", false},
+		{"invalid channel", "1.21.4", "beta", "latest", "", "", true},
+	}
+
+	t.Setenv("MCINIT_PAPER_API", PaperAPIV2)
+	p := NewPaperProvider()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			build, channel, err := p.ResolveChannelBuild(context.Background(), tt.version, tt.channel, tt.build)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveChannelBuild() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if build != tt.wantBuild || channel != tt.wantChannel {
+				t.Errorf("ResolveChannelBuild() = %q, %q, want %q, %q", build, channel, tt.wantBuild, tt.wantChannel)
+			}
+		})
+	}
+}
+
+func TestPaperProviderMirrorFailover(t *testing.T) {
+	server := newPaperTestServer(t, "folia")
+	outdated := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(outdated.Close)
+
+	// Folia has its own mirror setting, independent of Paper's
+	t.Setenv("MCINIT_PAPER_URL", outdated.URL)
+	t.Setenv("MCINIT_FOLIA_URL", outdated.URL+","+server.URL)
+	t.Setenv("MCINIT_FOLIA_API", PaperAPIV2)
+
+	p := NewFoliaProvider()
+	p.cache = useTestCache(t)
+
+	localPath, _, _, err := p.DownloadJar(context.Background(), "1.21.4", "101")
+	if err != nil {
+		t.Fatalf("DownloadJar() error = %v", err)
+	}
+	if data, _ := os.ReadFile(localPath); string(data) != paperTestJar {
+		t.Errorf("downloaded jar = %q", data)
+	}
+}
+
+func TestPaperProviderFill(t *testing.T) {
+	server := newFillTestServer(t, "paper")
+	retired := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(retired.Close)
+
+	t.Setenv("MCINIT_PAPER_URL", retired.URL)
+	t.Setenv("MCINIT_PAPER_FILL_URL", server.URL)
+	t.Setenv("MCINIT_PAPER_FILL_DATA_URL", server.URL)
+
+	p := NewPaperProvider()
+	p.cache = useTestCache(t)
+	ctx := context.Background()
+
+	versions, err := p.GetAvailableVersions(ctx)
+	if err != nil {
+		t.Fatalf("GetAvailableVersions() error = %v", err)
+	}
+	if want := []string{"1.16.5", "1.21.4", "1.21.5"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("GetAvailableVersions() = %v, want %v", versions, want)
+	}
+
+	// BETA builds are experimental
+	build, channel, err := p.ResolveChannelBuild(ctx, "1.21.4", PaperChannelExperimental, "latest")
+	if err != nil || build != "102" || channel != PaperChannelExperimental {
+		t.Errorf("ResolveChannelBuild(experimental) = %q, %q, %v, want 102, experimental", build, channel, err)
+	}
+	build, err = p.GetLatestBuild(ctx, "1.21.4")
+	if err != nil || build != "101" {
+		t.Errorf("GetLatestBuild() = %q, %v, want 101", build, err)
+	}
+
+	localPath, downloadURL, checksum, err := p.DownloadJar(ctx, "1.21.4", "latest")
+	if err != nil {
+		t.Fatalf("DownloadJar() error = %v", err)
+	}
+	sum := sha256.Sum256([]byte(paperTestJar))
+	if want := "https://fill-data.papermc.io/v1/objects/" + hex.EncodeToString(sum[:]) + "/paper-1.21.4-101.jar"; downloadURL != want {
+		t.Errorf("DownloadJar() URL = %s, want %s", downloadURL, want)
+	}
+	if checksum != hex.EncodeToString(sum[:]) {
+		t.Errorf("DownloadJar() checksum = %s", checksum)
+	}
+	if data, _ := os.ReadFile(localPath); string(data) != paperTestJar {
+		t.Errorf("downloaded jar = %q", data)
+	}
+
+	if java, err := p.RequiredJava(ctx, "1.16.5"); err != nil || java != 8 {
+		t.Errorf("RequiredJava() = %d, %v, want 8", java, err)
+	}
+
+	testPaperChanges(t, p)
+}
+
+func TestPaperProviderForcedV2(t *testing.T) {
+	server := newPaperTestServer(t, "paper")
+	var fillRequests atomic.Int32
+	fill := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fillRequests.Add(1)
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(fill.Close)
+
+	t.Setenv("MCINIT_PAPER_URL", server.URL)
+	t.Setenv("MCINIT_PAPER_FILL_URL", fill.URL)
+	t.Setenv("MCINIT_PAPER_FILL_DATA_URL", fill.URL)
+	t.Setenv("MCINIT_PAPER_API", PaperAPIV2)
+
+	p := NewPaperProvider()
+	p.cache = useTestCache(t)
+	ctx := context.Background()
+
+	if _, err := p.GetAvailableVersions(ctx); err != nil {
+		t.Errorf("GetAvailableVersions() error = %v", err)
+	}
+	if _, _, _, err := p.DownloadJar(ctx, "1.21.4", "latest"); err != nil {
+		t.Errorf("DownloadJar() error = %v", err)
+	}
+	if java, err := p.RequiredJava(ctx, "1.21.4"); err != nil || java != 0 {
+		t.Errorf("RequiredJava() = %d, %v, want 0 without the Fill API", java, err)
+	}
+	testPaperChanges(t, p)
+
+	if n := fillRequests.Load(); n != 0 {
+		t.Errorf("Fill API received %d requests, want none", n)
+	}
+
+	t.Setenv("MCINIT_PAPER_API", "v4")
+	if _, err := p.GetAvailableVersions(ctx); err == nil || !strings.Contains(err.Error(), "invalid MCINIT_PAPER_API") {
+		t.Errorf("GetAvailableVersions() error = %v, want an invalid API", err)
+	}
+}
+
+// testPaperChanges checks the changes of the newest 1.21.4 builds of the test
+// servers, which both APIs describe alike
+func testPaperChanges(t *testing.T, p *PaperProvider) {
+	t.Helper()
+
+	changes, err := p.Changes(context.Background(), "1.21.4")
+	if err != nil {
+		t.Fatalf("Changes() error = %v", err)
+	}
+
+	var builds []string
+	for _, c := range changes[:min(2, len(changes))] {
+		builds = append(builds, c.Build+" "+c.Channel)
+	}
+	if want := []string{"102 experimental", "101 default"}; !reflect.DeepEqual(builds, want) {
+		t.Fatalf("Changes() builds = %v, want %v", builds, want)
+	}
+
+	build := changes[1]
+	if got := build.Time.Format(time.DateOnly); got != "2025-01-05" {
+		t.Errorf("Changes() time = %s, want 2025-01-05", got)
+	}
+	if want := []Commit{{Hash: "abcdef0123456789", Summary: "Fix chunk loading"}}; !reflect.DeepEqual(build.Commits, want) {
+		t.Errorf("Changes() commits = %v, want %v", build.Commits, want)
+	}
+}
@@ -0,0 +1,287 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/jackh54/mcinit/internal/cache"
+)
+
+// pluginPrefix is the file name prefix of provider plugin executables
+const pluginPrefix = "mcinit-provider-"
+
+// pluginProtocol is the version of the plugin protocol sent with every request
+const pluginProtocol = 1
+
+// PluginRequest is written as JSON to a plugin's standard input. Method is
+// one of getAvailableVersions, getLatestBuild, getDownloadURL or getChecksum.
+type PluginRequest struct {
+	Protocol int    `json:"protocol"`
+	Method   string `json:"method"`
+	Version  string `json:"version,omitempty"`
+	Build    string `json:"build,omitempty"`
+}
+
+// PluginResponse is read as JSON from a plugin's standard output. Only the
+// fields of the requested method are set, or Error if it failed.
+type PluginResponse struct {
+	Versions  []string `json:"versions,omitempty"`
+	Build     string   `json:"build,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	Algorithm string   `json:"algorithm,omitempty"`
+	Checksum  string   `json:"checksum,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// PluginProvider implements Provider by running an external executable
+// named mcinit-provider-<name> once per request. The plugin resolves
+// versions, builds, URLs and checksums, mcinit downloads and verifies the jar.
+type PluginProvider struct {
+	cache *cache.Cache
+	name  string
+	path  string
+}
+
+// NewPluginProvider creates a provider for the plugin executable at path
+func NewPluginProvider(name, path string) *PluginProvider {
+	c, _ := cache.New()
+	return &PluginProvider{
+		cache: c,
+		name:  name,
+		path:  path,
+	}
+}
+
+// FindPlugins returns the plugin executables on PATH by provider name. The
+// first executable found for a name wins, as in command lookup.
+func FindPlugins() map[string]string {
+	plugins := make(map[string]string)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok || entry.IsDir() {
+				continue
+			}
+			if _, found := plugins[name]; found {
+				continue
+			}
+
+			path := filepath.Join(dir, entry.Name())
+			if isExecutable(path) {
+				plugins[name] = path
+			}
+		}
+	}
+	return plugins
+}
+
+// LoadPlugins registers a provider for every plugin on PATH and returns
+// their names in sorted order. Plugins that would replace a registered provider are skipped
+// and reported in the returned error.
+func LoadPlugins() ([]string, error) {
+	plugins := FindPlugins()
+	names := make([]string, 0, len(plugins))
+	for name := range plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var loaded []string
+	var errs []string
+	for _, name := range names {
+		path := plugins[name]
+		if Exists(name) {
+			errs = append(errs, fmt.Sprintf("%s: provider %s is already registered", path, name))
+			continue
+		}
+
+		Register(name, NewPluginProvider(name, path))
+		loaded = append(loaded, name)
+	}
+
+	if len(errs) > 0 {
+		return loaded, fmt.Errorf("failed to load provider plugins:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return loaded, nil
+}
+
+// pluginName returns the provider name of a plugin file name
+func pluginName(fileName string) (string, bool) {
+	if !strings.HasPrefix(fileName, pluginPrefix) {
+		return "", false
+	}
+
+	name := strings.TrimPrefix(fileName, pluginPrefix)
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(name))
+		if ext != ".exe" && ext != ".bat" && ext != ".cmd" {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+
+	if !manifestNamePattern.MatchString(name) {
+		return "", false
+	}
+	return name, true
+}
+
+// isExecutable reports whether path is a file the user can run
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	// Windows has no executable bit, pluginName already checked the extension
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
+
+// GetName returns the provider name
+func (p *PluginProvider) GetName() string {
+	return p.name
+}
+
+// GetAvailableVersions returns the versions the plugin lists
+func (p *PluginProvider) GetAvailableVersions(ctx context.Context) ([]string, error) {
+	resp, err := p.call(ctx, PluginRequest{Method: "getAvailableVersions"})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Versions, nil
+}
+
+// GetLatestBuild returns the latest build the plugin knows for a version
+func (p *PluginProvider) GetLatestBuild(ctx context.Context, version string) (string, error) {
+	resp, err := p.call(ctx, PluginRequest{Method: "getLatestBuild", Version: version})
+	if err != nil {
+		return "", err
+	}
+	return resp.Build, nil
+}
+
+// DownloadJar downloads the jar at the URL the plugin returns and verifies
+// it against the plugin's checksum, if it returns one
+func (p *PluginProvider) DownloadJar(ctx context.Context, version, build string) (string, string, string, error) {
+	build, err := p.resolveBuild(ctx, version, build)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	downloadURL, err := p.GetDownloadURL(ctx, version, build)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	algorithm, checksum, err := p.GetChecksum(ctx, version, build)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	downloader := cache.NewDownloader(p.cache)
+	localPath, err := downloader.DownloadJar(downloadURL, p.name, version, build, checksum, algorithm)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to download jar: %w", err)
+	}
+
+	return localPath, downloadURL, checksum, nil
+}
+
+// GetDownloadURL returns the jar URL the plugin returns for a build
+func (p *PluginProvider) GetDownloadURL(ctx context.Context, version, build string) (string, error) {
+	build, err := p.resolveBuild(ctx, version, build)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := p.call(ctx, PluginRequest{Method: "getDownloadURL", Version: version, Build: build})
+	if err != nil {
+		return "", err
+	}
+	if resp.URL == "" {
+		return "", fmt.Errorf("plugin %s returned no download URL", p.name)
+	}
+	return resp.URL, nil
+}
+
+// GetChecksum returns the checksum the plugin returns for a build, which may
+// be empty if the distribution publishes none
+func (p *PluginProvider) GetChecksum(ctx context.Context, version, build string) (string, string, error) {
+	build, err := p.resolveBuild(ctx, version, build)
+	if err != nil {
+		return "", "", err
+	}
+
+	resp, err := p.call(ctx, PluginRequest{Method: "getChecksum", Version: version, Build: build})
+	if err != nil {
+		return "", "", err
+	}
+
+	if resp.Checksum == "" {
+		return "", "", nil
+	}
+	switch resp.Algorithm {
+	case "sha256", "sha1", "md5":
+		return resp.Algorithm, resp.Checksum, nil
+	default:
+		return "", "", fmt.Errorf("plugin %s returned unsupported checksum algorithm: %q", p.name, resp.Algorithm)
+	}
+}
+
+// resolveBuild resolves "latest" to the latest build
+func (p *PluginProvider) resolveBuild(ctx context.Context, version, build string) (string, error) {
+	if build != "" && build != "latest" {
+		return build, nil
+	}
+
+	latest, err := p.GetLatestBuild(ctx, version)
+	if err != nil {
+		return "", fmt.Errorf("failed to get latest build: %w", err)
+	}
+	return latest, nil
+}
+
+// call runs the plugin with a request on its standard input and decodes the
+// response from its standard output
+func (p *PluginProvider) call(ctx context.Context, req PluginRequest) (*PluginResponse, error) {
+	req.Protocol = pluginProtocol
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("plugin %s failed: %w: %s", p.name, err, msg)
+		}
+		return nil, fmt.Errorf("plugin %s failed: %w", p.name, err)
+	}
+
+	var resp PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response of plugin %s: %w", p.name, err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("plugin %s: %s", p.name, resp.Error)
+	}
+
+	return &resp, nil
+}
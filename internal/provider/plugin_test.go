@@ -0,0 +1,188 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// pluginTestJar is the jar content the fake plugin reports the checksum of
+const pluginTestJar = "plugin server jar"
+
+// installFakePlugin builds the reference plugin in testdata/fakeplugin as
+// an executable named mcinit-provider-<name> in a new directory and returns
+// the directory
+func installFakePlugin(t *testing.T, name string) string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin names require an extension on Windows")
+	}
+
+	dir := t.TempDir()
+	goTool := filepath.Join(runtime.GOROOT(), "bin", "go")
+	cmd := exec.Command(goTool, "build", "-o", filepath.Join(dir, pluginPrefix+name), "./testdata/fakeplugin")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build the fake plugin: %v\n%s", err, output)
+	}
+	return dir
+}
+
+// newTestPluginProvider installs the fake plugin with a jar server and an
+// empty cache
+func newTestPluginProvider(t *testing.T) *PluginProvider {
+	t.Helper()
+
+	dir := installFakePlugin(t, "fake")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fake-1.21.4-12.jar" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, pluginTestJar)
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("MCINIT_FAKE_PLUGIN_URL", server.URL)
+
+	p := NewPluginProvider("fake", filepath.Join(dir, pluginPrefix+"fake"))
+	p.cache = useTestCache(t)
+	return p
+}
+
+func TestPluginName(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin names require an extension on Windows")
+	}
+
+	tests := []struct {
+		fileName string
+		want     string
+		wantOK   bool
+	}{
+		{"mcinit-provider-pufferfish", "pufferfish", true},
+		{"mcinit-provider-internal_mirror", "internal_mirror", true},
+		{"mcinit-provider-", "", false},
+		{"mcinit-provider-Bad Name", "", false},
+		{"mcinit", "", false},
+		{"other-tool", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			got, ok := pluginName(tt.fileName)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("pluginName() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFindPlugins(t *testing.T) {
+	first := installFakePlugin(t, "fake")
+	second := installFakePlugin(t, "fake")
+
+	// Not executable
+	if err := os.WriteFile(filepath.Join(second, pluginPrefix+"other"), []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", strings.Join([]string{first, filepath.Join(first, "missing"), second}, string(os.PathListSeparator)))
+
+	want := map[string]string{"fake": filepath.Join(first, pluginPrefix+"fake")}
+	if got := FindPlugins(); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindPlugins() = %v, want %v", got, want)
+	}
+}
+
+func TestPluginProvider(t *testing.T) {
+	p := newTestPluginProvider(t)
+	ctx := context.Background()
+
+	versions, err := p.GetAvailableVersions(ctx)
+	if err != nil {
+		t.Fatalf("GetAvailableVersions() error = %v", err)
+	}
+	if want := []string{"1.21.3", "1.21.4"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("GetAvailableVersions() = %v, want %v", versions, want)
+	}
+
+	build, err := p.GetLatestBuild(ctx, "1.21.4")
+	if err != nil || build != "12" {
+		t.Errorf("GetLatestBuild() = %q, %v, want 12", build, err)
+	}
+
+	localPath, downloadURL, checksum, err := p.DownloadJar(ctx, "1.21.4", "latest")
+	if err != nil {
+		t.Fatalf("DownloadJar() error = %v", err)
+	}
+	if !strings.HasSuffix(downloadURL, "/fake-1.21.4-12.jar") {
+		t.Errorf("DownloadJar() URL = %s", downloadURL)
+	}
+	sum := sha256.Sum256([]byte(pluginTestJar))
+	if checksum != hex.EncodeToString(sum[:]) {
+		t.Errorf("DownloadJar() checksum = %s", checksum)
+	}
+	if data, _ := os.ReadFile(localPath); string(data) != pluginTestJar {
+		t.Errorf("downloaded jar = %q", data)
+	}
+	if !p.cache.HasJar("fake", "1.21.4", "12") {
+		t.Error("DownloadJar() should cache the jar under the plugin name")
+	}
+}
+
+func TestPluginProviderErrors(t *testing.T) {
+	p := newTestPluginProvider(t)
+	ctx := context.Background()
+
+	// Errors reported in the response
+	_, err := p.GetLatestBuild(ctx, "1.8.8")
+	if err == nil || !strings.Contains(err.Error(), "unknown version 1.8.8") {
+		t.Errorf("GetLatestBuild() error = %v, want the plugin's error", err)
+	}
+
+	// Plugins that exit with an error
+	_, err = p.call(ctx, PluginRequest{Method: "install", Version: "1.21.4"})
+	if err == nil || !strings.Contains(err.Error(), "unknown method install") {
+		t.Errorf("call() error = %v, want the plugin's output", err)
+	}
+}
+
+func TestLoadPlugins(t *testing.T) {
+	dir := installFakePlugin(t, "test-plugin")
+	for _, name := range []string{"paper", "another-plugin"} {
+		if err := os.Link(filepath.Join(dir, pluginPrefix+"test-plugin"), filepath.Join(dir, pluginPrefix+name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+	t.Cleanup(func() {
+		delete(providers, "test-plugin")
+		delete(providers, "another-plugin")
+	})
+
+	loaded, err := LoadPlugins()
+	if want := []string{"another-plugin", "test-plugin"}; !reflect.DeepEqual(loaded, want) {
+		t.Errorf("LoadPlugins() = %v, want %v", loaded, want)
+	}
+	if err == nil || !strings.Contains(err.Error(), "provider paper is already registered") {
+		t.Errorf("LoadPlugins() error = %v, want the paper plugin reported", err)
+	}
+
+	p, err := Get("test-plugin")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, ok := p.(*PluginProvider); !ok {
+		t.Errorf("Get() = %T, want *PluginProvider", p)
+	}
+}
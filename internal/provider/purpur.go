@@ -11,6 +11,9 @@ import (
 	"github.com/jackh54/mcinit/internal/cache"
 )
 
+// purpurAPIURL is the base URL of the Purpur downloads API
+const purpurAPIURL = "https://api.purpurmc.org"
+
 // PurpurProvider implements Provider for Purpur
 type PurpurProvider struct {
 	cache  *cache.Cache
@@ -21,10 +24,8 @@ type PurpurProvider struct {
 func NewPurpurProvider() *PurpurProvider {
 	c, _ := cache.New()
 	return &PurpurProvider{
-		cache: c,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		cache:  c,
+		client: newHTTPClient(Mirror{"purpur", purpurAPIURL}),
 	}
 }
 
@@ -35,7 +36,7 @@ func (p *PurpurProvider) GetName() string {
 
 // GetAvailableVersions returns all available Minecraft versions
 func (p *PurpurProvider) GetAvailableVersions(ctx context.Context) ([]string, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.purpurmc.org/v2/purpur", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", purpurAPIURL+"/v2/purpur", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -67,7 +68,7 @@ func (p *PurpurProvider) GetAvailableVersions(ctx context.Context) ([]string, er
 
 // GetLatestBuild returns the latest build for a version
 func (p *PurpurProvider) GetLatestBuild(ctx context.Context, version string) (string, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("https://api.purpurmc.org/v2/purpur/%s", version), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/v2/purpur/%s", purpurAPIURL, version), nil)
 	if err != nil {
 		return "", err
 	}
@@ -99,6 +100,56 @@ func (p *PurpurProvider) GetLatestBuild(ctx context.Context, version string) (st
 	return result.Builds.Latest, nil
 }
 
+// Changes returns the builds of a version with their commits, newest first.
+// Builds that failed are included and marked as Failed.
+func (p *PurpurProvider) Changes(ctx context.Context, version string) ([]BuildChanges, error) {
+	url := fmt.Sprintf("%s/v2/purpur/%s?detailed=true", purpurAPIURL, version)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Builds struct {
+			All []PurpurBuild `json:"all"`
+		} `json:"builds"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	// Builds are listed oldest first
+	builds := result.Builds.All
+	changes := make([]BuildChanges, len(builds))
+	for i, b := range builds {
+		c := BuildChanges{Build: b.Build}
+		if b.Timestamp > 0 {
+			c.Time = time.UnixMilli(b.Timestamp).UTC()
+		}
+		c.Failed = b.Result != "" && b.Result != "SUCCESS"
+		for _, commit := range b.Commits {
+			c.Commits = append(c.Commits, Commit{Hash: commit.Hash, Summary: commitSummary(commit.Description)})
+		}
+		changes[len(changes)-1-i] = c
+	}
+	return changes, nil
+}
+
 // DownloadJar downloads the Purpur server jar
 func (p *PurpurProvider) DownloadJar(ctx context.Context, version, build string) (string, string, string, error) {
 	if build == "" || build == "latest" {
@@ -109,10 +160,10 @@ func (p *PurpurProvider) DownloadJar(ctx context.Context, version, build string)
 		build = latestBuild
 	}
 
-	downloadURL := fmt.Sprintf("https://api.purpurmc.org/v2/purpur/%s/%s/download", version, build)
+	downloadURL := fmt.Sprintf("%s/v2/purpur/%s/%s/download", purpurAPIURL, version, build)
 
 	// Purpur doesn't provide checksums in the API, so we'll skip verification
-	downloader := cache.NewDownloader(p.cache)
+	downloader := newDownloader(p.cache, p.client)
 	localPath, err := downloader.DownloadJar(downloadURL, "purpur", version, build, "", "")
 	if err != nil {
 		return "", "", "", fmt.Errorf("failed to download jar: %w", err)
@@ -131,10 +182,29 @@ func (p *PurpurProvider) GetDownloadURL(ctx context.Context, version, build stri
 		build = latestBuild
 	}
 
-	return fmt.Sprintf("https://api.purpurmc.org/v2/purpur/%s/%s/download", version, build), nil
+	return fmt.Sprintf("%s/v2/purpur/%s/%s/download", purpurAPIURL, version, build), nil
 }
 
 // GetChecksum returns empty (Purpur doesn't provide checksums)
 func (p *PurpurProvider) GetChecksum(ctx context.Context, version, build string) (string, string, error) {
 	return "", "", nil
 }
+
+// Purpur API structures
+
+type PurpurBuild struct {
+	Project   string         `json:"project"`
+	Version   string         `json:"version"`
+	Build     string         `json:"build"`
+	Result    string         `json:"result"`
+	Timestamp int64          `json:"timestamp"` // milliseconds since the epoch
+	Commits   []PurpurCommit `json:"commits"`
+	MD5       string         `json:"md5"`
+}
+
+type PurpurCommit struct {
+	Author      string `json:"author"`
+	Description string `json:"description"`
+	Hash        string `json:"hash"`
+	Timestamp   int64  `json:"timestamp"`
+}
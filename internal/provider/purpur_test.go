@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPurpurChanges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/purpur/1.21.4" || r.URL.Query().Get("detailed") != "true" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"project":"purpur","version":"1.21.4","builds":{"latest":"2400","all":[
+			{"build":"2400","result":"SUCCESS","timestamp":1735000000000,"commits":[
+				{"author":"granny","description":"Updated Upstream (Paper)\n\nUpstream has released updates","hash":"0123456789abcdef"}]},
+			{"build":"2401","result":"FAILURE","timestamp":1735100000000,"commits":[]}]}}`)
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("MCINIT_PURPUR_URL", server.URL)
+
+	changes, err := NewPurpurProvider().Changes(context.Background(), "1.21.4")
+	if err != nil {
+		t.Fatalf("Changes() error = %v", err)
+	}
+
+	want := []BuildChanges{
+		{Build: "2401", Time: time.UnixMilli(1735100000000).UTC(), Failed: true},
+		{
+			Build:   "2400",
+			Time:    time.UnixMilli(1735000000000).UTC(),
+			Commits: []Commit{{Hash: "0123456789abcdef", Summary: "Updated Upstream (Paper)"}},
+		},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Changes() = %+v, want %+v", changes, want)
+	}
+}
@@ -0,0 +1,295 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/jackh54/mcinit/internal/cache"
+	"github.com/jackh54/mcinit/internal/utils"
+)
+
+// Quilt meta API and the Maven repository installers are downloaded from
+const (
+	quiltMetaURL  = "https://meta.quiltmc.org"
+	quiltMavenURL = "https://maven.quiltmc.org"
+)
+
+// quiltLaunchJar is the launch jar written by the Quilt installer
+const quiltLaunchJar = "quilt-server-launch.jar"
+
+// QuiltProvider implements Provider for Quilt. Builds combine a loader and an
+// installer version, see LoaderBuild. DownloadJar returns the installer jar,
+// Install sets up the server with it.
+type QuiltProvider struct {
+	cache  *cache.Cache
+	client *http.Client
+}
+
+// NewQuiltProvider creates a new QuiltProvider
+func NewQuiltProvider() *QuiltProvider {
+	c, _ := cache.New()
+	return &QuiltProvider{
+		cache:  c,
+		client: newHTTPClient(Mirror{"quilt", quiltMetaURL}, Mirror{"quilt-maven", quiltMavenURL}),
+	}
+}
+
+// GetName returns the provider name
+func (p *QuiltProvider) GetName() string {
+	return "quilt"
+}
+
+// GetAvailableVersions returns the stable Minecraft versions supported by Quilt
+func (p *QuiltProvider) GetAvailableVersions(ctx context.Context) ([]string, error) {
+	var games []LoaderVersion
+	if err := fetchJSON(ctx, p.client, quiltMetaURL+"/v3/versions/game", &games); err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(games))
+	for _, game := range games {
+		if game.Stable {
+			versions = append(versions, game.Version)
+		}
+	}
+
+	return versions, nil
+}
+
+// GetLatestBuild returns the latest stable loader and installer for a version
+func (p *QuiltProvider) GetLatestBuild(ctx context.Context, version string) (string, error) {
+	loader, installer, err := p.ResolveLoader(ctx, version, "", "")
+	if err != nil {
+		return "", err
+	}
+	return p.LoaderBuild(loader, installer), nil
+}
+
+// ResolveLoader resolves the loader and installer versions for a Minecraft
+// version. Empty or "latest" versions resolve to the latest stable release.
+func (p *QuiltProvider) ResolveLoader(ctx context.Context, version, loader, installer string) (string, string, error) {
+	loaders, err := p.getLoaders(ctx, version)
+	if err != nil {
+		return "", "", err
+	}
+
+	if loader == "" || loader == "latest" {
+		loader = latestLoaderVersion(loaders)
+	} else if !hasLoaderVersion(loaders, loader) {
+		return "", "", fmt.Errorf("quilt loader %s is not available for Minecraft %s", loader, version)
+	}
+
+	installers, err := p.getInstallers(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	if installer == "" || installer == "latest" {
+		installer = latestLoaderVersion(installers)
+		if installer == "" {
+			return "", "", fmt.Errorf("no Quilt installer versions available")
+		}
+	} else if !hasLoaderVersion(installers, installer) {
+		return "", "", fmt.Errorf("quilt installer %s not found", installer)
+	}
+
+	return loader, installer, nil
+}
+
+// LoaderBuild encodes a loader and installer version as a build
+func (p *QuiltProvider) LoaderBuild(loader, installer string) string {
+	return joinLoaderBuild(loader, installer)
+}
+
+// SplitLoaderBuild decodes a build created by LoaderBuild
+func (p *QuiltProvider) SplitLoaderBuild(build string) (string, string, error) {
+	return splitLoaderBuild("Quilt", build)
+}
+
+// ServerJarName returns the launch jar written by the installer
+func (p *QuiltProvider) ServerJarName() string {
+	return quiltLaunchJar
+}
+
+// DownloadJar downloads the Quilt installer jar for a build
+func (p *QuiltProvider) DownloadJar(ctx context.Context, version, build string) (string, string, string, error) {
+	downloadURL, err := p.GetDownloadURL(ctx, version, build)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	_, checksum, err := p.GetChecksum(ctx, version, build)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	_, installer, err := p.splitBuild(ctx, version, build)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	downloader := newDownloader(p.cache, p.client)
+	localPath, err := downloader.DownloadJar(downloadURL, "quilt-installer", installer, "", checksum, "sha1")
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to download installer: %w", err)
+	}
+
+	return localPath, downloadURL, checksum, nil
+}
+
+// GetDownloadURL returns the URL of the installer jar
+func (p *QuiltProvider) GetDownloadURL(ctx context.Context, version, build string) (string, error) {
+	_, installer, err := p.splitBuild(ctx, version, build)
+	if err != nil {
+		return "", err
+	}
+
+	installers, err := p.getInstallerEntries(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for _, entry := range installers {
+		if entry.Version == installer {
+			return entry.URL, nil
+		}
+	}
+	return "", fmt.Errorf("quilt installer %s not found", installer)
+}
+
+// GetChecksum returns the SHA-1 checksum published next to the installer jar
+func (p *QuiltProvider) GetChecksum(ctx context.Context, version, build string) (string, string, error) {
+	downloadURL, err := p.GetDownloadURL(ctx, version, build)
+	if err != nil {
+		return "", "", err
+	}
+
+	text, err := fetchText(ctx, p.client, downloadURL+".sha1")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fetch installer checksum: %w", err)
+	}
+
+	// Checksum files may be followed by the file name
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", "", fmt.Errorf("empty installer checksum")
+	}
+	return "sha1", fields[0], nil
+}
+
+// Install runs the Quilt installer in serverDir, which writes the launch jar,
+// its libraries and the vanilla server jar
+func (p *QuiltProvider) Install(ctx context.Context, serverDir, javaPath, version, build string) (*InstallResult, error) {
+	loader, _, err := p.splitBuild(ctx, version, build)
+	if err != nil {
+		return nil, err
+	}
+
+	installerPath, downloadURL, checksum, err := p.DownloadJar(ctx, version, build)
+	if err != nil {
+		return nil, err
+	}
+
+	absDir, err := filepath.Abs(serverDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve server directory: %w", err)
+	}
+
+	err = runInstaller(ctx, javaPath, absDir, installerPath,
+		"install", "server", version, loader, "--download-server", "--install-dir="+absDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if !utils.PathExists(filepath.Join(absDir, quiltLaunchJar)) {
+		return nil, fmt.Errorf("installer did not create %s", quiltLaunchJar)
+	}
+
+	return &InstallResult{
+		JarPath:     quiltLaunchJar,
+		DownloadURL: downloadURL,
+		Checksum:    checksum,
+		Algorithm:   "sha1",
+	}, nil
+}
+
+// splitBuild resolves "latest" and splits a build into loader and installer
+func (p *QuiltProvider) splitBuild(ctx context.Context, version, build string) (string, string, error) {
+	if build == "" || build == "latest" {
+		latest, err := p.GetLatestBuild(ctx, version)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get latest build: %w", err)
+		}
+		build = latest
+	}
+	return p.SplitLoaderBuild(build)
+}
+
+// getLoaders returns the loader versions for a Minecraft version, newest first
+func (p *QuiltProvider) getLoaders(ctx context.Context, version string) ([]LoaderVersion, error) {
+	var entries []QuiltLoaderEntry
+	if err := fetchJSON(ctx, p.client, quiltMetaURL+"/v3/versions/loader/"+url.PathEscape(version), &entries); err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("quilt does not support Minecraft %s", version)
+	}
+
+	loaders := make([]LoaderVersion, 0, len(entries))
+	for _, entry := range entries {
+		loaders = append(loaders, quiltVersion(entry.Loader.Version))
+	}
+	return loaders, nil
+}
+
+// getInstallers returns the installer versions, newest first
+func (p *QuiltProvider) getInstallers(ctx context.Context) ([]LoaderVersion, error) {
+	entries, err := p.getInstallerEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	installers := make([]LoaderVersion, 0, len(entries))
+	for _, entry := range entries {
+		installers = append(installers, quiltVersion(entry.Version))
+	}
+	return installers, nil
+}
+
+// getInstallerEntries returns the installer listing of the meta API
+func (p *QuiltProvider) getInstallerEntries(ctx context.Context) ([]QuiltInstallerEntry, error) {
+	var entries []QuiltInstallerEntry
+	if err := fetchJSON(ctx, p.client, quiltMetaURL+"/v3/versions/installer", &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// quiltVersion marks pre-releases such as 0.28.0-beta.1 as unstable, the
+// Quilt meta API does not flag them
+func quiltVersion(version string) LoaderVersion {
+	return LoaderVersion{
+		Version: version,
+		Stable:  !strings.Contains(version, "-"),
+	}
+}
+
+// Quilt meta API structures
+
+type QuiltLoaderEntry struct {
+	Loader struct {
+		Version string `json:"version"`
+		Maven   string `json:"maven"`
+	} `json:"loader"`
+}
+
+type QuiltInstallerEntry struct {
+	URL     string `json:"url"`
+	Maven   string `json:"maven"`
+	Version string `json:"version"`
+}
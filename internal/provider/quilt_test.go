@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const quiltTestInstaller = "installer jar"
+
+// newTestQuiltProvider serves a minimal Quilt meta API and maven repository
+func newTestQuiltProvider(t *testing.T) *QuiltProvider {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	t.Setenv("MCINIT_QUILT_URL", server.URL)
+	t.Setenv("MCINIT_QUILT_MAVEN_URL", server.URL)
+
+	installerURL := quiltMavenURL + "/maven/quilt-installer-0.9.2.jar"
+	sum := sha1.Sum([]byte(quiltTestInstaller))
+
+	mux.HandleFunc("/v3/versions/game", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"version":"1.21.4","stable":true},{"version":"24w14a","stable":false}]`)
+	})
+	mux.HandleFunc("/v3/versions/loader/1.21.4", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"loader":{"version":"0.28.0-beta.1"}},{"loader":{"version":"0.27.1"}},{"loader":{"version":"0.27.0"}}]`)
+	})
+	mux.HandleFunc("/v3/versions/installer", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"url":%q,"version":"0.9.2"}]`, installerURL)
+	})
+	mux.HandleFunc("/maven/quilt-installer-0.9.2.jar", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, quiltTestInstaller)
+	})
+	mux.HandleFunc("/maven/quilt-installer-0.9.2.jar.sha1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, hex.EncodeToString(sum[:]))
+	})
+
+	p := NewQuiltProvider()
+	p.cache = useTestCache(t)
+	return p
+}
+
+func TestQuiltResolveLoader(t *testing.T) {
+	p := newTestQuiltProvider(t)
+
+	loader, installer, err := p.ResolveLoader(context.Background(), "1.21.4", "latest", "latest")
+	if err != nil {
+		t.Fatalf("ResolveLoader() error = %v", err)
+	}
+	// Betas are skipped when resolving the latest loader
+	if loader != "0.27.1" || installer != "0.9.2" {
+		t.Errorf("ResolveLoader() = (%s, %s), want (0.27.1, 0.9.2)", loader, installer)
+	}
+
+	loader, _, err = p.ResolveLoader(context.Background(), "1.21.4", "0.28.0-beta.1", "")
+	if err != nil || loader != "0.28.0-beta.1" {
+		t.Errorf("ResolveLoader() pinned beta = %s, %v", loader, err)
+	}
+
+	build := p.LoaderBuild("0.28.0-beta.1", "0.9.2")
+	if l, i, err := p.SplitLoaderBuild(build); err != nil || l != "0.28.0-beta.1" || i != "0.9.2" {
+		t.Errorf("SplitLoaderBuild(%q) = (%s, %s, %v)", build, l, i, err)
+	}
+}
+
+func TestQuiltInstall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake installer is a shell script")
+	}
+
+	p := newTestQuiltProvider(t)
+
+	// A fake java that records its arguments and writes the launch jar
+	binDir := t.TempDir()
+	javaPath := filepath.Join(binDir, "java")
+	script := `#!/bin/sh
+echo "$@" > "` + filepath.Join(binDir, "args") + `"
+for arg in "$@"; do
+  case "$arg" in
+    --install-dir=*) touch "${arg#--install-dir=}/quilt-server-launch.jar" ;;
+  esac
+done
+`
+	if err := os.WriteFile(javaPath, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake java: %v", err)
+	}
+
+	serverDir := t.TempDir()
+	result, err := p.Install(context.Background(), serverDir, javaPath, "1.21.4", "0.27.1-0.9.2")
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	if result.JarPath != "quilt-server-launch.jar" {
+		t.Errorf("Install() jar = %s, want quilt-server-launch.jar", result.JarPath)
+	}
+	if result.Algorithm != "sha1" || result.Checksum == "" {
+		t.Errorf("Install() checksum = %s:%s", result.Algorithm, result.Checksum)
+	}
+
+	args, err := os.ReadFile(filepath.Join(binDir, "args"))
+	if err != nil {
+		t.Fatalf("Installer was not run: %v", err)
+	}
+	if !strings.Contains(string(args), "install server 1.21.4 0.27.1 --download-server") {
+		t.Errorf("Installer args = %q", args)
+	}
+}
+
+func TestQuiltInstallFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake installer is a shell script")
+	}
+
+	p := newTestQuiltProvider(t)
+
+	javaPath := filepath.Join(t.TempDir(), "java")
+	if err := os.WriteFile(javaPath, []byte("#!/bin/sh\necho 'Unsupported Minecraft version'\nexit 1\n"), 0755); err != nil {
+		t.Fatalf("Failed to write fake java: %v", err)
+	}
+
+	_, err := p.Install(context.Background(), t.TempDir(), javaPath, "1.21.4", "0.27.1-0.9.2")
+	if err == nil || !strings.Contains(err.Error(), "Unsupported Minecraft version") {
+		t.Errorf("Install() error = %v, want installer output", err)
+	}
+}
@@ -44,5 +44,14 @@ func init() {
 	Register("velocity", NewVelocityProvider())
 	Register("waterfall", NewWaterfallProvider())
 	Register("bungee", NewBungeeProvider())
+	Register("fabric", NewFabricProvider())
+	Register("quilt", NewQuiltProvider())
+	Register("forge", NewForgeProvider())
+	Register("neoforge", NewNeoForgeProvider())
+	Register("spigot", NewSpigotProvider())
+	Register("craftbukkit", NewCraftBukkitProvider())
+	Register("sponge", NewSpongeProvider())
+	Register("spongeforge", NewSpongeForgeProvider())
+	Register("custom", NewCustomProvider())
 }
 
@@ -14,6 +14,15 @@ func TestProviderRegistry(t *testing.T) {
 		"velocity",
 		"waterfall",
 		"bungee",
+		"fabric",
+		"quilt",
+		"forge",
+		"neoforge",
+		"spigot",
+		"craftbukkit",
+		"sponge",
+		"spongeforge",
+		"custom",
 	}
 
 	for _, name := range expectedProviders {
@@ -53,7 +62,7 @@ func TestList(t *testing.T) {
 	}
 
 	// Check that it includes expected providers
-	expectedCount := 7 // vanilla, paper, purpur, folia, velocity, waterfall, bungee
+	expectedCount := 16 // vanilla, paper, purpur, folia, velocity, waterfall, bungee, fabric, quilt, forge, neoforge, spigot, craftbukkit, sponge, spongeforge, custom
 	if len(providers) != expectedCount {
 		t.Errorf("List() returned %d providers, expected %d", len(providers), expectedCount)
 	}
@@ -0,0 +1,340 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackh54/mcinit/internal/cache"
+	"github.com/jackh54/mcinit/internal/java"
+	"github.com/jackh54/mcinit/internal/utils"
+)
+
+// SpigotMC build infrastructure
+const (
+	spigotHubURL       = "https://hub.spigotmc.org"
+	buildToolsJobURL   = spigotHubURL + "/jenkins/job/BuildTools"
+	spigotVersionsURL  = spigotHubURL + "/versions"
+	spigotBuildDataURL = spigotHubURL + "/stash/projects/SPIGOT/repos/builddata/raw/info.json"
+)
+
+// buildToolsJar is the file name of the BuildTools artifact
+const buildToolsJar = "BuildTools.jar"
+
+// spigotVersionFilePattern matches Minecraft versions in the versions listing,
+// which also contains files named after build numbers
+var spigotVersionFilePattern = regexp.MustCompile(`href="(\d+\.\d+(?:\.\d+)?(?:-[\w.]+)?)\.json"`)
+
+// SpigotProvider implements Provider for Spigot and CraftBukkit, which can
+// only be obtained by compiling them with BuildTools. Builds are the Spigot
+// build numbers of the versions API. Install runs BuildTools in a work
+// directory under the cache and caches the resulting jar, so later installs
+// of the same build only copy it.
+type SpigotProvider struct {
+	cache    *cache.Cache
+	client   *http.Client
+	name     string    // "spigot" or "craftbukkit", passed to --compile
+	progress io.Writer // receives the BuildTools output
+}
+
+// NewSpigotProvider creates a provider for Spigot
+func NewSpigotProvider() *SpigotProvider {
+	return newSpigotProvider("spigot")
+}
+
+// NewCraftBukkitProvider creates a provider for CraftBukkit
+func NewCraftBukkitProvider() *SpigotProvider {
+	return newSpigotProvider("craftbukkit")
+}
+
+func newSpigotProvider(name string) *SpigotProvider {
+	c, _ := cache.New()
+	return &SpigotProvider{
+		cache:    c,
+		name:     name,
+		progress: os.Stdout,
+		client:   newHTTPClient(Mirror{name, spigotHubURL}),
+	}
+}
+
+// GetName returns the provider name
+func (p *SpigotProvider) GetName() string {
+	return p.name
+}
+
+// GetAvailableVersions returns the Minecraft versions BuildTools can build,
+// oldest first
+func (p *SpigotProvider) GetAvailableVersions(ctx context.Context) ([]string, error) {
+	listing, err := fetchText(ctx, p.client, spigotVersionsURL+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, match := range spigotVersionFilePattern.FindAllStringSubmatch(listing, -1) {
+		versions = append(versions, match[1])
+	}
+
+	// The listing is sorted by name, which puts 1.10 before 1.9
+	sort.SliceStable(versions, func(i, j int) bool {
+		return compareVersions(versions[i], versions[j]) < 0
+	})
+	return versions, nil
+}
+
+// GetLatestBuild returns the Spigot build number of a version
+func (p *SpigotProvider) GetLatestBuild(ctx context.Context, version string) (string, error) {
+	info, err := p.getVersion(ctx, version)
+	if err != nil {
+		return "", err
+	}
+	return info.Name, nil
+}
+
+// DownloadJar downloads BuildTools. The server jar itself is built by Install.
+func (p *SpigotProvider) DownloadJar(ctx context.Context, version, build string) (string, string, string, error) {
+	info, err := fetchJenkinsBuild(ctx, p.client, buildToolsJobURL, "lastSuccessfulBuild")
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to find BuildTools: %w", err)
+	}
+
+	downloadURL, err := info.artifactURL(buildToolsJobURL, buildToolsJar)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	checksum, err := info.fingerprint(buildToolsJar)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	downloader := newDownloader(p.cache, p.client)
+	localPath, err := downloader.DownloadJar(downloadURL, "buildtools", strconv.Itoa(info.Number), "", checksum, "md5")
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to download BuildTools: %w", err)
+	}
+
+	return localPath, downloadURL, checksum, nil
+}
+
+// GetDownloadURL returns the URL of the latest BuildTools jar
+func (p *SpigotProvider) GetDownloadURL(ctx context.Context, version, build string) (string, error) {
+	info, err := fetchJenkinsBuild(ctx, p.client, buildToolsJobURL, "lastSuccessfulBuild")
+	if err != nil {
+		return "", fmt.Errorf("failed to find BuildTools: %w", err)
+	}
+	return info.artifactURL(buildToolsJobURL, buildToolsJar)
+}
+
+// GetChecksum returns the MD5 fingerprint of the latest BuildTools jar
+func (p *SpigotProvider) GetChecksum(ctx context.Context, version, build string) (string, string, error) {
+	info, err := fetchJenkinsBuild(ctx, p.client, buildToolsJobURL, "lastSuccessfulBuild")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to find BuildTools: %w", err)
+	}
+
+	checksum, err := info.fingerprint(buildToolsJar)
+	if err != nil {
+		return "", "", err
+	}
+	return "md5", checksum, nil
+}
+
+// Install builds the server jar with BuildTools, or takes it from the cache,
+// and copies it into serverDir as server.jar. BuildTools is run with a Java
+// version the requested revision supports, preferring javaPath.
+func (p *SpigotProvider) Install(ctx context.Context, serverDir, javaPath, version, build string) (*InstallResult, error) {
+	// BuildTools accepts a version or a build number as revision
+	rev := version
+	if build != "" && build != "latest" {
+		rev = build
+	}
+
+	info, err := p.getVersion(ctx, rev)
+	if err != nil {
+		return nil, err
+	}
+
+	// Builds are cached and recorded under the version, so an explicit
+	// build must belong to it
+	if rev != version {
+		if err := p.checkBuildVersion(ctx, info, version); err != nil {
+			return nil, err
+		}
+	}
+
+	jarPath, err := p.buildJar(ctx, javaPath, version, rev, info)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := utils.CopyFile(jarPath, filepath.Join(serverDir, "server.jar")); err != nil {
+		return nil, fmt.Errorf("failed to copy server jar: %w", err)
+	}
+
+	meta, err := p.cache.GetMetadata(p.name, version, info.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InstallResult{
+		JarPath:     "server.jar",
+		DownloadURL: meta.DownloadURL,
+		Checksum:    meta.Checksum,
+		Algorithm:   meta.Algorithm,
+	}, nil
+}
+
+// buildJar returns the cached jar of a build, running BuildTools first if
+// it has not been built yet
+func (p *SpigotProvider) buildJar(ctx context.Context, javaPath, version, rev string, info *SpigotVersion) (string, error) {
+	if p.cache.HasJar(p.name, version, info.Name) {
+		fmt.Printf("Using cached %s %s build %s\n", p.name, version, info.Name)
+		return p.cache.GetJarPath(p.name, version, info.Name), nil
+	}
+
+	javaPath, err := selectJava(javaPath, info.JavaVersions)
+	if err != nil {
+		return "", fmt.Errorf("no Java installation can run BuildTools for %s: %w", rev, err)
+	}
+
+	buildTools, downloadURL, _, err := p.DownloadJar(ctx, version, info.Name)
+	if err != nil {
+		return "", err
+	}
+
+	// BuildTools keeps its git checkouts in the work directory, which
+	// makes later builds of the same type and version faster
+	workDir := filepath.Join(p.cache.GetBaseDir(), "buildtools", p.name+"-"+version)
+	outputDir := filepath.Join(workDir, "output")
+	if err := os.RemoveAll(outputDir); err != nil {
+		return "", fmt.Errorf("failed to clean BuildTools output: %w", err)
+	}
+	if err := utils.EnsureDir(outputDir); err != nil {
+		return "", fmt.Errorf("failed to create BuildTools work directory: %w", err)
+	}
+
+	finalName := fmt.Sprintf("%s-%s.jar", p.name, version)
+	fmt.Printf("Running BuildTools for %s %s (this can take several minutes)...\n", p.name, rev)
+	err = streamInstaller(ctx, javaPath, workDir, buildTools, p.progress,
+		"--rev", rev, "--compile", p.name, "--output-dir", outputDir, "--final-name", finalName)
+	if err != nil {
+		return "", fmt.Errorf("BuildTools %w", err)
+	}
+
+	builtJar := filepath.Join(outputDir, finalName)
+	if !utils.PathExists(builtJar) {
+		return "", fmt.Errorf("BuildTools did not create %s", finalName)
+	}
+
+	return p.cache.StoreJar(builtJar, p.name, version, info.Name, downloadURL)
+}
+
+// getVersion fetches the versions API entry for a version or build number
+func (p *SpigotProvider) getVersion(ctx context.Context, rev string) (*SpigotVersion, error) {
+	var info SpigotVersion
+	if err := fetchJSON(ctx, p.client, fmt.Sprintf("%s/%s.json", spigotVersionsURL, rev), &info); err != nil {
+		return nil, fmt.Errorf("failed to get %s revision %s: %w", p.name, rev, err)
+	}
+
+	if info.Name == "" {
+		return nil, fmt.Errorf("%s revision %s has no build number", p.name, rev)
+	}
+	return &info, nil
+}
+
+// checkBuildVersion checks that a build compiles the given Minecraft
+// version, which is recorded in the BuildData revision of the build
+func (p *SpigotProvider) checkBuildVersion(ctx context.Context, info *SpigotVersion, version string) error {
+	if info.Refs.BuildData == "" {
+		return fmt.Errorf("%s build %s has no BuildData revision", p.name, info.Name)
+	}
+
+	var buildData struct {
+		MinecraftVersion string `json:"minecraftVersion"`
+	}
+	url := spigotBuildDataURL + "?at=" + info.Refs.BuildData
+	if err := fetchJSON(ctx, p.client, url, &buildData); err != nil {
+		return fmt.Errorf("failed to get the Minecraft version of %s build %s: %w", p.name, info.Name, err)
+	}
+
+	if buildData.MinecraftVersion != version {
+		return fmt.Errorf("%s build %s is for Minecraft %s, not %s", p.name, info.Name, buildData.MinecraftVersion, version)
+	}
+	return nil
+}
+
+// compareVersions compares two Minecraft versions such as 1.21.4 or
+// 1.14-pre5 part by part. A pre-release sorts before its release.
+func compareVersions(a, b string) int {
+	aRelease, aPre, _ := strings.Cut(a, "-")
+	bRelease, bPre, _ := strings.Cut(b, "-")
+
+	aParts, bParts := strings.Split(aRelease, "."), strings.Split(bRelease, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var x, y int
+		if i < len(aParts) {
+			x, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			y, _ = strconv.Atoi(bParts[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return strings.Compare(aPre, bPre)
+}
+
+// selectJava returns javaPath if its version is within the supported range
+// of class file versions, or else another installation that is
+func selectJava(javaPath string, classVersions []int) (string, error) {
+	if len(classVersions) < 2 {
+		return javaPath, nil
+	}
+
+	// Class file version 52 is Java 8
+	minMajor, maxMajor := classVersions[0]-44, classVersions[1]-44
+
+	detector := java.NewDetector()
+	if inst, err := detector.DetectVersion(javaPath); err == nil && inst.Major >= minMajor && inst.Major <= maxMajor {
+		return javaPath, nil
+	}
+
+	inst, err := detector.FindInRange(minMajor, maxMajor)
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("Using Java %s at %s for BuildTools\n", inst.Version, inst.Path)
+	return inst.Path, nil
+}
+
+// SpigotMC versions API structures
+
+type SpigotVersion struct {
+	Name         string `json:"name"` // build number
+	Description  string `json:"description"`
+	JavaVersions []int  `json:"javaVersions"` // supported class file versions, min and max
+	Refs         struct {
+		BuildData string `json:"BuildData"` // commit of the BuildData repository
+	} `json:"refs"`
+}
@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const spigotTestBuildTools = "buildtools jar"
+
+// newTestSpigotProvider serves a minimal BuildTools job, versions API and
+// BuildData repository. Build 4399 is for 1.21.4 and build 4390 for 1.21.3.
+func newTestSpigotProvider(t *testing.T) *SpigotProvider {
+	t.Helper()
+
+	sum := md5.Sum([]byte(spigotTestBuildTools))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jenkins/job/BuildTools/lastSuccessfulBuild/api/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"number":190,"result":"SUCCESS",
+			"artifacts":[{"fileName":"BuildTools.jar","relativePath":"target/BuildTools.jar"}],
+			"fingerprint":[{"fileName":"BuildTools.jar","hash":%q}]}`, hex.EncodeToString(sum[:]))
+	})
+	mux.HandleFunc("/jenkins/job/BuildTools/190/artifact/target/BuildTools.jar", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, spigotTestBuildTools)
+	})
+	mux.HandleFunc("/versions/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body><pre><a href="../">../</a>
+<a href="1.10.2.json">1.10.2.json</a>
+<a href="1.14-pre5.json">1.14-pre5.json</a>
+<a href="1.14.json">1.14.json</a>
+<a href="1.21.3.json">1.21.3.json</a>
+<a href="1.21.4.json">1.21.4.json</a>
+<a href="1.9.4.json">1.9.4.json</a>
+<a href="4390.json">4390.json</a>
+</pre></body></html>`)
+	})
+	mux.HandleFunc("/versions/1.21.4.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"4400","description":"Jenkins build 4400"}`)
+	})
+	mux.HandleFunc("/versions/4390.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"4390","description":"Jenkins build 4390","refs":{"BuildData":"b1213"}}`)
+	})
+	mux.HandleFunc("/versions/4399.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"4399","description":"Jenkins build 4399","refs":{"BuildData":"b1214"}}`)
+	})
+	mux.HandleFunc("/stash/projects/SPIGOT/repos/builddata/raw/info.json", func(w http.ResponseWriter, r *http.Request) {
+		versions := map[string]string{"b1213": "1.21.3", "b1214": "1.21.4"}
+		fmt.Fprintf(w, `{"minecraftVersion":%q}`, versions[r.URL.Query().Get("at")])
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	t.Setenv("MCINIT_SPIGOT_URL", server.URL)
+
+	p := NewSpigotProvider()
+	p.cache = useTestCache(t)
+	p.progress = io.Discard
+	return p
+}
+
+func TestSpigotVersions(t *testing.T) {
+	p := newTestSpigotProvider(t)
+	ctx := context.Background()
+
+	versions, err := p.GetAvailableVersions(ctx)
+	if err != nil {
+		t.Fatalf("GetAvailableVersions() error = %v", err)
+	}
+	if want := []string{"1.9.4", "1.10.2", "1.14-pre5", "1.14", "1.21.3", "1.21.4"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("GetAvailableVersions() = %v, want %v", versions, want)
+	}
+
+	build, err := p.GetLatestBuild(ctx, "1.21.4")
+	if err != nil || build != "4400" {
+		t.Errorf("GetLatestBuild() = %s, %v, want 4400", build, err)
+	}
+
+	algorithm, checksum, err := p.GetChecksum(ctx, "1.21.4", "4400")
+	if err != nil || algorithm != "md5" || len(checksum) != 32 {
+		t.Errorf("GetChecksum() = %s:%s, %v", algorithm, checksum, err)
+	}
+}
+
+func TestSpigotInstall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake BuildTools is a shell script")
+	}
+
+	p := newTestSpigotProvider(t)
+	ctx := context.Background()
+
+	// A fake java that logs every run and writes the jar BuildTools would
+	binDir := t.TempDir()
+	javaPath := filepath.Join(binDir, "java")
+	runsPath := filepath.Join(binDir, "runs")
+	script := `#!/bin/sh
+echo "$@" >> "` + runsPath + `"
+while [ $# -gt 0 ]; do
+  case "$1" in
+    --output-dir) out="$2"; shift ;;
+    --final-name) name="$2"; shift ;;
+  esac
+  shift
+done
+echo "built by $(pwd)" > "$out/$name"
+`
+	if err := os.WriteFile(javaPath, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake java: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		serverDir := t.TempDir()
+		result, err := p.Install(ctx, serverDir, javaPath, "1.21.4", "latest")
+		if err != nil {
+			t.Fatalf("Install() error = %v", err)
+		}
+
+		if result.JarPath != "server.jar" || result.Algorithm != "sha256" || result.Checksum == "" {
+			t.Errorf("Install() = %+v", result)
+		}
+
+		data, err := os.ReadFile(filepath.Join(serverDir, "server.jar"))
+		if err != nil {
+			t.Fatalf("server.jar was not installed: %v", err)
+		}
+		workDir := filepath.Join(p.cache.GetBaseDir(), "buildtools", "spigot-1.21.4")
+		if !strings.Contains(string(data), workDir) {
+			t.Errorf("BuildTools ran in %q, want %s", data, workDir)
+		}
+	}
+
+	runs, err := os.ReadFile(runsPath)
+	if err != nil {
+		t.Fatalf("BuildTools was not run: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(runs)), "\n")
+	if len(lines) != 1 {
+		t.Errorf("BuildTools ran %d times, want once with the second install cached", len(lines))
+	}
+	if !strings.Contains(lines[0], "--rev 1.21.4 --compile spigot") {
+		t.Errorf("BuildTools args = %q", lines[0])
+	}
+}
+
+func TestSpigotInstallFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake BuildTools is a shell script")
+	}
+
+	p := newTestSpigotProvider(t)
+
+	javaPath := filepath.Join(t.TempDir(), "java")
+	if err := os.WriteFile(javaPath, []byte("#!/bin/sh\necho 'Could not compile'\nexit 1\n"), 0755); err != nil {
+		t.Fatalf("Failed to write fake java: %v", err)
+	}
+
+	_, err := p.Install(context.Background(), t.TempDir(), javaPath, "1.21.4", "4399")
+	if err == nil || !strings.Contains(err.Error(), "Could not compile") {
+		t.Errorf("Install() error = %v, want BuildTools output", err)
+	}
+}
+
+func TestSpigotInstallBuildOfOtherVersion(t *testing.T) {
+	p := newTestSpigotProvider(t)
+
+	// Fails before BuildTools would be run with the missing java
+	javaPath := filepath.Join(t.TempDir(), "java")
+	_, err := p.Install(context.Background(), t.TempDir(), javaPath, "1.21.4", "4390")
+	if err == nil || !strings.Contains(err.Error(), "build 4390 is for Minecraft 1.21.3, not 1.21.4") {
+		t.Errorf("Install() error = %v, want a version mismatch", err)
+	}
+	if p.cache.HasJar("spigot", "1.21.4", "4390") {
+		t.Error("Install() cached a jar of another version")
+	}
+}
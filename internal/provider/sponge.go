@@ -0,0 +1,315 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+
+	"github.com/jackh54/mcinit/internal/cache"
+	"github.com/jackh54/mcinit/internal/utils"
+)
+
+// SpongePowered downloads API and the repository its assets are served from
+const (
+	spongeAPIURL  = "https://dl-api.spongepowered.org"
+	spongeRepoURL = "https://repo.spongepowered.org"
+)
+
+// spongeDownloadsURL is the artifact group of the SpongePowered downloads API
+const spongeDownloadsURL = spongeAPIURL + "/v2/groups/org.spongepowered/artifacts"
+
+// SpongeProvider implements Provider for SpongeVanilla using the
+// SpongePowered downloads API. Builds are artifact versions such as
+// 1.21.4-13.0.0, and "recommended" selects the newest recommended build.
+type SpongeProvider struct {
+	cache    *cache.Cache
+	client   *http.Client
+	name     string
+	artifact string
+}
+
+// NewSpongeProvider creates a provider for SpongeVanilla
+func NewSpongeProvider() *SpongeProvider {
+	return newSpongeProvider("sponge", "spongevanilla")
+}
+
+func newSpongeProvider(name, artifact string) *SpongeProvider {
+	c, _ := cache.New()
+	return &SpongeProvider{
+		cache:    c,
+		name:     name,
+		artifact: artifact,
+		client:   newHTTPClient(Mirror{name, spongeAPIURL}, Mirror{name + "-repo", spongeRepoURL}),
+	}
+}
+
+// GetName returns the provider name
+func (p *SpongeProvider) GetName() string {
+	return p.name
+}
+
+// GetAvailableVersions returns the Minecraft versions with Sponge builds
+func (p *SpongeProvider) GetAvailableVersions(ctx context.Context) ([]string, error) {
+	var artifact SpongeArtifact
+	if err := fetchJSON(ctx, p.client, p.artifactURL(), &artifact); err != nil {
+		return nil, err
+	}
+	return artifact.Tags["minecraft"], nil
+}
+
+// GetLatestBuild returns the newest build for a version
+func (p *SpongeProvider) GetLatestBuild(ctx context.Context, version string) (string, error) {
+	return p.ResolveBuild(ctx, version, "", "latest")
+}
+
+// ResolveBuild resolves "latest" or "recommended" to the newest (recommended)
+// build for a Minecraft version, limited to builds of apiVersion if it is
+// not empty. Other builds are checked to belong to the version.
+func (p *SpongeProvider) ResolveBuild(ctx context.Context, version, apiVersion, build string) (string, error) {
+	if build != "" && build != "latest" && build != "recommended" {
+		info, err := p.getBuild(ctx, build)
+		if err != nil {
+			return "", err
+		}
+		if info.Tags["minecraft"] != version {
+			return "", fmt.Errorf("%s build %s is for Minecraft %s, not %s", p.name, build, info.Tags["minecraft"], version)
+		}
+		if apiVersion != "" && info.Tags["api"] != apiVersion {
+			return "", fmt.Errorf("%s build %s targets API %s, not %s", p.name, build, info.Tags["api"], apiVersion)
+		}
+		return build, nil
+	}
+
+	tags := "minecraft:" + version
+	if apiVersion != "" {
+		tags += ",api:" + apiVersion
+	}
+	query := url.Values{"tags": {tags}, "limit": {"1"}}
+	if build == "recommended" {
+		query.Set("recommended", "true")
+	}
+
+	var versions SpongeVersions
+	if err := fetchJSON(ctx, p.client, p.artifactURL()+"/versions?"+query.Encode(), &versions); err != nil {
+		return "", err
+	}
+
+	// Versions are listed newest first, so the single result is the newest
+	for name := range versions.Artifacts {
+		return name, nil
+	}
+
+	kind := "builds"
+	if build == "recommended" {
+		kind = "recommended builds"
+	}
+	if apiVersion != "" {
+		return "", fmt.Errorf("no %s %s for Minecraft %s with API %s", p.name, kind, version, apiVersion)
+	}
+	return "", fmt.Errorf("no %s %s for Minecraft %s", p.name, kind, version)
+}
+
+// DownloadJar downloads the jar of a build
+func (p *SpongeProvider) DownloadJar(ctx context.Context, version, build string) (string, string, string, error) {
+	build, asset, err := p.resolveAsset(ctx, version, build)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	algorithm, checksum := asset.checksum()
+	downloader := newDownloader(p.cache, p.client)
+	localPath, err := downloader.DownloadJar(asset.DownloadURL, p.artifact, version, build, checksum, algorithm)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to download jar: %w", err)
+	}
+
+	return localPath, asset.DownloadURL, checksum, nil
+}
+
+// GetDownloadURL returns the download URL of the jar of a build
+func (p *SpongeProvider) GetDownloadURL(ctx context.Context, version, build string) (string, error) {
+	_, asset, err := p.resolveAsset(ctx, version, build)
+	if err != nil {
+		return "", err
+	}
+	return asset.DownloadURL, nil
+}
+
+// GetChecksum returns the SHA-1 checksum of the jar of a build, or its MD5
+// checksum if no SHA-1 is published
+func (p *SpongeProvider) GetChecksum(ctx context.Context, version, build string) (string, string, error) {
+	_, asset, err := p.resolveAsset(ctx, version, build)
+	if err != nil {
+		return "", "", err
+	}
+
+	algorithm, checksum := asset.checksum()
+	if checksum == "" {
+		return "", "", fmt.Errorf("no checksum published for %s", asset.DownloadURL)
+	}
+	return algorithm, checksum, nil
+}
+
+// resolveAsset resolves a build and returns it with its jar asset
+func (p *SpongeProvider) resolveAsset(ctx context.Context, version, build string) (string, *SpongeAsset, error) {
+	build, err := p.ResolveBuild(ctx, version, "", build)
+	if err != nil {
+		return "", nil, err
+	}
+
+	info, err := p.getBuild(ctx, build)
+	if err != nil {
+		return "", nil, err
+	}
+
+	asset, err := info.jar()
+	if err != nil {
+		return "", nil, fmt.Errorf("%s build %s: %w", p.name, build, err)
+	}
+	return build, asset, nil
+}
+
+// getBuild fetches the details of a build
+func (p *SpongeProvider) getBuild(ctx context.Context, build string) (*SpongeBuild, error) {
+	var info SpongeBuild
+	if err := fetchJSON(ctx, p.client, p.artifactURL()+"/versions/"+url.PathEscape(build), &info); err != nil {
+		return nil, fmt.Errorf("failed to get build info: %w", err)
+	}
+	return &info, nil
+}
+
+// artifactURL returns the API URL of the artifact
+func (p *SpongeProvider) artifactURL() string {
+	return spongeDownloadsURL + "/" + p.artifact
+}
+
+// SpongeForgeProvider implements Provider for SpongeForge, which is a Forge
+// mod. Install sets up the Forge server the build was made for and puts the
+// SpongeForge jar into its mods directory.
+type SpongeForgeProvider struct {
+	*SpongeProvider
+	forge *ForgeProvider
+}
+
+// NewSpongeForgeProvider creates a provider for SpongeForge
+func NewSpongeForgeProvider() *SpongeForgeProvider {
+	return &SpongeForgeProvider{
+		SpongeProvider: newSpongeProvider("spongeforge", "spongeforge"),
+		forge:          NewForgeProvider(),
+	}
+}
+
+// Install installs Forge and the SpongeForge mod into serverDir
+func (p *SpongeForgeProvider) Install(ctx context.Context, serverDir, javaPath, version, build string) (*InstallResult, error) {
+	build, err := p.ResolveBuild(ctx, version, "", build)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := p.getBuild(ctx, build)
+	if err != nil {
+		return nil, err
+	}
+
+	asset, err := info.jar()
+	if err != nil {
+		return nil, fmt.Errorf("spongeforge build %s: %w", build, err)
+	}
+
+	forgeBuild := info.Tags["forge"]
+	if forgeBuild == "" {
+		return nil, fmt.Errorf("spongeforge build %s does not name its Forge version", build)
+	}
+
+	fmt.Printf("Installing Forge %s for SpongeForge %s...\n", forgeBuild, build)
+	result, err := p.forge.Install(ctx, serverDir, javaPath, version, forgeBuild)
+	if err != nil {
+		return nil, fmt.Errorf("failed to install Forge: %w", err)
+	}
+
+	localPath, downloadURL, checksum, err := p.DownloadJar(ctx, version, build)
+	if err != nil {
+		return nil, err
+	}
+
+	modsDir := filepath.Join(serverDir, "mods")
+	if err := utils.EnsureDir(modsDir); err != nil {
+		return nil, fmt.Errorf("failed to create mods directory: %w", err)
+	}
+	if err := utils.CopyFile(localPath, filepath.Join(modsDir, fmt.Sprintf("spongeforge-%s.jar", build))); err != nil {
+		return nil, fmt.Errorf("failed to copy SpongeForge: %w", err)
+	}
+
+	algorithm, _ := asset.checksum()
+	result.DownloadURL = downloadURL
+	result.Checksum = checksum
+	result.Algorithm = algorithm
+	return result, nil
+}
+
+// SpongePowered downloads API structures
+
+type SpongeArtifact struct {
+	DisplayName string              `json:"displayName"`
+	Tags        map[string][]string `json:"tags"`
+}
+
+type SpongeVersions struct {
+	Artifacts map[string]struct {
+		TagValues   map[string]string `json:"tagValues"`
+		Recommended bool              `json:"recommended"`
+	} `json:"artifacts"`
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+	Size   int `json:"size"`
+}
+
+type SpongeBuild struct {
+	Assets      []SpongeAsset     `json:"assets"`
+	Tags        map[string]string `json:"tags"`
+	Recommended bool              `json:"recommended"`
+}
+
+type SpongeAsset struct {
+	Classifier  string `json:"classifier"`
+	DownloadURL string `json:"downloadUrl"`
+	Extension   string `json:"extension"`
+	MD5         string `json:"md5"`
+	SHA1        string `json:"sha1"`
+}
+
+// jar returns the runnable jar of a build: the universal jar, or the jar
+// without a classifier for builds that have no universal jar
+func (b *SpongeBuild) jar() (*SpongeAsset, error) {
+	var plain *SpongeAsset
+	for i := range b.Assets {
+		asset := &b.Assets[i]
+		if asset.Extension != "jar" {
+			continue
+		}
+		switch asset.Classifier {
+		case "universal":
+			return asset, nil
+		case "":
+			plain = asset
+		}
+	}
+
+	if plain == nil {
+		return nil, fmt.Errorf("no server jar published")
+	}
+	return plain, nil
+}
+
+// checksum returns the strongest checksum published for the asset
+func (a *SpongeAsset) checksum() (string, string) {
+	if a.SHA1 != "" {
+		return "sha1", a.SHA1
+	}
+	if a.MD5 != "" {
+		return "md5", a.MD5
+	}
+	return "", ""
+}
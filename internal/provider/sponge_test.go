@@ -0,0 +1,191 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/jackh54/mcinit/internal/utils"
+)
+
+const spongeTestJar = "sponge jar"
+
+// newSpongeTestServer serves a minimal downloads API and repository for an
+// artifact. The versions endpoint answers like the real one for the queries
+// used.
+func newSpongeTestServer(t *testing.T, artifact string, builds map[string]string) *httptest.Server {
+	t.Helper()
+
+	sum := sha1.Sum([]byte(spongeTestJar))
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	base := "/v2/groups/org.spongepowered/artifacts/" + artifact
+	mux.HandleFunc(base, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"displayName":"Sponge","tags":{"api":["12","13"],"minecraft":["1.21.4","1.21.3"]}}`)
+	})
+	mux.HandleFunc(base+"/versions", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("limit") != "1" {
+			t.Errorf("versions query without limit=1: %s", r.URL.RawQuery)
+		}
+
+		// Newest first: 13.0.1 is a beta of API 13, 12.0.5 is recommended
+		var name string
+		switch {
+		case query.Get("tags") == "minecraft:1.21.4" && query.Get("recommended") == "true":
+			name = "1.21.4-12.0.5"
+		case query.Get("tags") == "minecraft:1.21.4":
+			name = "1.21.4-13.0.1"
+		case query.Get("tags") == "minecraft:1.21.4,api:12":
+			name = "1.21.4-12.0.5"
+		}
+
+		if name == "" {
+			fmt.Fprint(w, `{"artifacts":{},"offset":0,"limit":1,"size":0}`)
+			return
+		}
+		fmt.Fprintf(w, `{"artifacts":{%q:{"tagValues":{"minecraft":"1.21.4"},"recommended":false}},"offset":0,"limit":1,"size":3}`, name)
+	})
+	for build, tags := range builds {
+		build, tags := build, tags
+		jarPath := "/maven/" + artifact + "-" + build + "-universal.jar"
+		mux.HandleFunc(base+"/versions/"+build, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"assets":[
+				{"classifier":"","downloadUrl":%q,"extension":"pom","sha1":"unused"},
+				{"classifier":"universal","downloadUrl":%q,"extension":"jar","md5":"unused","sha1":%q}
+			],"tags":%s,"recommended":false}`, spongeRepoURL+"/maven/pom", spongeRepoURL+jarPath, hex.EncodeToString(sum[:]), tags)
+		})
+		mux.HandleFunc(jarPath, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, spongeTestJar)
+		})
+	}
+
+	return server
+}
+
+func newTestSpongeProvider(t *testing.T, p *SpongeProvider, builds map[string]string) *SpongeProvider {
+	t.Helper()
+
+	server := newSpongeTestServer(t, p.artifact, builds)
+	t.Setenv(MirrorEnv(p.name), server.URL)
+	t.Setenv(MirrorEnv(p.name+"-repo"), server.URL)
+
+	p.cache = useTestCache(t)
+	return p
+}
+
+var spongeTestBuilds = map[string]string{
+	"1.21.4-13.0.1": `{"minecraft":"1.21.4","api":"13"}`,
+	"1.21.4-12.0.5": `{"minecraft":"1.21.4","api":"12"}`,
+	"1.21.3-12.0.2": `{"minecraft":"1.21.3","api":"12"}`,
+}
+
+func TestSpongeVersions(t *testing.T) {
+	p := newTestSpongeProvider(t, NewSpongeProvider(), spongeTestBuilds)
+
+	versions, err := p.GetAvailableVersions(context.Background())
+	if err != nil {
+		t.Fatalf("GetAvailableVersions() error = %v", err)
+	}
+	if want := []string{"1.21.4", "1.21.3"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("GetAvailableVersions() = %v, want %v", versions, want)
+	}
+}
+
+func TestSpongeResolveBuild(t *testing.T) {
+	p := newTestSpongeProvider(t, NewSpongeProvider(), spongeTestBuilds)
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		version    string
+		apiVersion string
+		build      string
+		want       string
+		wantErr    bool
+	}{
+		{"latest", "1.21.4", "", "latest", "1.21.4-13.0.1", false},
+		{"recommended", "1.21.4", "", "recommended", "1.21.4-12.0.5", false},
+		{"api filter", "1.21.4", "12", "latest", "1.21.4-12.0.5", false},
+		{"no builds for api", "1.21.4", "8", "latest", "", true},
+		{"pinned", "1.21.4", "13", "1.21.4-13.0.1", "1.21.4-13.0.1", false},
+		{"pinned for another version", "1.21.4", "", "1.21.3-12.0.2", "", true},
+		{"pinned for another api", "1.21.4", "12", "1.21.4-13.0.1", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			build, err := p.ResolveBuild(ctx, tt.version, tt.apiVersion, tt.build)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveBuild() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if build != tt.want {
+				t.Errorf("ResolveBuild() = %s, want %s", build, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpongeDownloadJar(t *testing.T) {
+	p := newTestSpongeProvider(t, NewSpongeProvider(), spongeTestBuilds)
+	ctx := context.Background()
+
+	algorithm, checksum, err := p.GetChecksum(ctx, "1.21.4", "recommended")
+	if err != nil || algorithm != "sha1" {
+		t.Fatalf("GetChecksum() = %s:%s, %v", algorithm, checksum, err)
+	}
+
+	localPath, downloadURL, got, err := p.DownloadJar(ctx, "1.21.4", "recommended")
+	if err != nil {
+		t.Fatalf("DownloadJar() error = %v", err)
+	}
+	if got != checksum || !strings.HasSuffix(downloadURL, "spongevanilla-1.21.4-12.0.5-universal.jar") {
+		t.Errorf("DownloadJar() = %s, %s", downloadURL, got)
+	}
+	if data, err := os.ReadFile(localPath); err != nil || string(data) != spongeTestJar {
+		t.Errorf("Downloaded jar = %q, %v", data, err)
+	}
+}
+
+func TestSpongeForgeInstall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake installer is a shell script")
+	}
+
+	p := NewSpongeForgeProvider()
+	newTestSpongeProvider(t, p.SpongeProvider, map[string]string{
+		"1.21.4-13.0.1": `{"minecraft":"1.21.4","api":"13","forge":"54.0.26"}`,
+	})
+	p.forge = newTestForgeProvider(t, p.forge, "1.21.4-54.0.26")
+	p.forge.cache = p.cache
+
+	javaPath, _ := writeFakeInstaller(t,
+		"mkdir -p libraries/net/minecraftforge/forge/1.21.4-54.0.26 && touch libraries/net/minecraftforge/forge/1.21.4-54.0.26/unix_args.txt\n")
+
+	serverDir := t.TempDir()
+	result, err := p.Install(context.Background(), serverDir, javaPath, "1.21.4", "latest")
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	if result.ArgsFile != "libraries/net/minecraftforge/forge/1.21.4-54.0.26/unix_args.txt" {
+		t.Errorf("Install() args file = %q", result.ArgsFile)
+	}
+	if result.Algorithm != "sha1" || !strings.HasSuffix(result.DownloadURL, "spongeforge-1.21.4-13.0.1-universal.jar") {
+		t.Errorf("Install() = %+v", result)
+	}
+	if !utils.PathExists(filepath.Join(serverDir, "mods", "spongeforge-1.21.4-13.0.1.jar")) {
+		t.Error("SpongeForge was not copied into mods")
+	}
+}
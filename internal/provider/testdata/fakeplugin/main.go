@@ -0,0 +1,77 @@
+// Command fakeplugin is a reference mcinit provider plugin, used by the
+// plugin tests. Installed on PATH as mcinit-provider-<name>, it provides a
+// distribution with builds 11 and 12 of Minecraft 1.21.4, whose jars are
+// served under the base URL in MCINIT_FAKE_PLUGIN_URL.
+//
+// mcinit runs the plugin once per request: it reads a single JSON request
+// from standard input and writes a single JSON response to standard output.
+// Errors about the request, such as an unknown version, are reported in the
+// response. Anything else exits non-zero with a message on standard error.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// jarContent is the content of every jar the fake distribution serves
+const jarContent = "plugin server jar"
+
+// request is what mcinit writes to standard input
+type request struct {
+	Protocol int    `json:"protocol"`
+	Method   string `json:"method"`
+	Version  string `json:"version,omitempty"`
+	Build    string `json:"build,omitempty"`
+}
+
+// response is what mcinit reads from standard output. Only the fields of
+// the requested method are set, or Error if it failed.
+type response struct {
+	Versions  []string `json:"versions,omitempty"`
+	Build     string   `json:"build,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	Algorithm string   `json:"algorithm,omitempty"`
+	Checksum  string   `json:"checksum,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var req request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+	if req.Protocol != 1 {
+		return fmt.Errorf("unsupported protocol %d", req.Protocol)
+	}
+
+	var resp response
+	switch {
+	case req.Method == "getAvailableVersions":
+		resp.Versions = []string{"1.21.3", "1.21.4"}
+	case req.Version != "1.21.4":
+		resp.Error = fmt.Sprintf("unknown version %s", req.Version)
+	case req.Method == "getLatestBuild":
+		resp.Build = "12"
+	case req.Method == "getDownloadURL":
+		resp.URL = fmt.Sprintf("%s/fake-%s-%s.jar", os.Getenv("MCINIT_FAKE_PLUGIN_URL"), req.Version, req.Build)
+	case req.Method == "getChecksum":
+		sum := sha256.Sum256([]byte(jarContent))
+		resp.Algorithm = "sha256"
+		resp.Checksum = hex.EncodeToString(sum[:])
+	default:
+		return fmt.Errorf("unknown method %s", req.Method)
+	}
+
+	return json.NewEncoder(os.Stdout).Encode(resp)
+}
@@ -6,11 +6,20 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/jackh54/mcinit/internal/cache"
 )
 
+// Mojang's version metadata and the host the server jars are downloaded from
+const (
+	mojangMetaURL = "https://piston-meta.mojang.com"
+	mojangDataURL = "https://piston-data.mojang.com"
+)
+
 // VanillaProvider implements Provider for Mojang's official server
 type VanillaProvider struct {
 	cache  *cache.Cache
@@ -21,10 +30,8 @@ type VanillaProvider struct {
 func NewVanillaProvider() *VanillaProvider {
 	c, _ := cache.New()
 	return &VanillaProvider{
-		cache: c,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		cache:  c,
+		client: newHTTPClient(Mirror{"vanilla", mojangMetaURL}, Mirror{"vanilla-data", mojangDataURL}),
 	}
 }
 
@@ -33,24 +40,78 @@ func (p *VanillaProvider) GetName() string {
 	return "vanilla"
 }
 
-// GetAvailableVersions returns all available Minecraft versions
+// GetAvailableVersions returns the release versions, newest first
 func (p *VanillaProvider) GetAvailableVersions(ctx context.Context) ([]string, error) {
+	return p.GetVersionsInChannel(ctx, "release")
+}
+
+// Channels returns the version channels of the version manifest. Snapshots
+// are the weekly snapshots, pre-releases include release candidates.
+func (p *VanillaProvider) Channels() []string {
+	return []string{"release", "snapshot", "pre-release", "old_beta", "old_alpha", "all"}
+}
+
+// GetVersionsInChannel returns the versions of a channel, newest first by
+// release time
+func (p *VanillaProvider) GetVersionsInChannel(ctx context.Context, channel string) ([]string, error) {
+	valid := false
+	for _, c := range p.Channels() {
+		if c == channel {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, fmt.Errorf("invalid channel %q (available: %s)", channel, strings.Join(p.Channels(), ", "))
+	}
+
 	manifest, err := p.fetchVersionManifest(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	versions := make([]string, 0, len(manifest.Versions))
+	entries := make([]VersionEntry, 0, len(manifest.Versions))
 	for _, v := range manifest.Versions {
-		// Only include release versions by default
-		if v.Type == "release" {
-			versions = append(versions, v.ID)
+		if channel == "all" || v.Channel() == channel {
+			entries = append(entries, v)
 		}
 	}
 
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].releaseTime().After(entries[j].releaseTime())
+	})
+
+	versions := make([]string, len(entries))
+	for i, v := range entries {
+		versions[i] = v.ID
+	}
 	return versions, nil
 }
 
+// ResolveVersion resolves the aliases "latest-release" and "latest-snapshot"
+// to the versions the manifest names as latest. The latest snapshot may be
+// a pre-release, or the latest release if it is newer. Other versions are
+// returned unchanged.
+func (p *VanillaProvider) ResolveVersion(ctx context.Context, version string) (string, error) {
+	if version != "latest-release" && version != "latest-snapshot" {
+		return version, nil
+	}
+
+	manifest, err := p.fetchVersionManifest(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch version manifest: %w", err)
+	}
+
+	latest := manifest.Latest.Release
+	if version == "latest-snapshot" {
+		latest = manifest.Latest.Snapshot
+	}
+	if latest == "" {
+		return "", fmt.Errorf("version manifest does not name a %s version", version)
+	}
+	return latest, nil
+}
+
 // GetLatestBuild returns empty string (vanilla has no builds)
 func (p *VanillaProvider) GetLatestBuild(ctx context.Context, version string) (string, error) {
 	return "", nil
@@ -90,7 +151,7 @@ func (p *VanillaProvider) DownloadJar(ctx context.Context, version, build string
 	checksum := versionInfo.Downloads.Server.SHA1
 
 	// Download using cache/downloader
-	downloader := cache.NewDownloader(p.cache)
+	downloader := newDownloader(p.cache, p.client)
 	localPath, err := downloader.DownloadJar(downloadURL, "vanilla", version, "", checksum, "sha1")
 	if err != nil {
 		return "", "", "", fmt.Errorf("failed to download jar: %w", err)
@@ -155,7 +216,7 @@ func (p *VanillaProvider) GetChecksum(ctx context.Context, version, build string
 
 // fetchVersionManifest fetches the Mojang version manifest
 func (p *VanillaProvider) fetchVersionManifest(ctx context.Context) (*VersionManifest, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://launchermeta.mojang.com/mc/game/version_manifest_v2.json", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", mojangMetaURL+"/mc/game/version_manifest_v2.json", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -231,6 +292,26 @@ type VersionEntry struct {
 	ReleaseTime string `json:"releaseTime"`
 }
 
+// preReleasePattern matches the IDs of pre-releases and release candidates,
+// which the manifest lists as snapshots: 1.21.4-pre1, 1.21.4-rc1 and the
+// older 1.14 Pre-Release 1
+var preReleasePattern = regexp.MustCompile(`-(pre|rc)\d+$| Pre-Release \d+$`)
+
+// Channel returns the channel of a version: its type, or "pre-release" for
+// snapshots that are pre-releases or release candidates
+func (v VersionEntry) Channel() string {
+	if v.Type == "snapshot" && preReleasePattern.MatchString(v.ID) {
+		return "pre-release"
+	}
+	return v.Type
+}
+
+// releaseTime returns the parsed release time, the zero time if it is invalid
+func (v VersionEntry) releaseTime() time.Time {
+	t, _ := time.Parse(time.RFC3339, v.ReleaseTime)
+	return t
+}
+
 type VersionInfo struct {
 	ID        string `json:"id"`
 	Type      string `json:"type"`
@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+)
+
+const vanillaTestJar = "vanilla server jar"
+
+func TestVanillaProvider(t *testing.T) {
+	sum := sha1.Sum([]byte(vanillaTestJar))
+
+	// Mojang serves metadata and jars from different hosts, and so do
+	// proxies that mirror them
+	meta := http.NewServeMux()
+	meta.HandleFunc("/mc/game/version_manifest_v2.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"latest":{"release":"1.21.4","snapshot":"25w02a"},"versions":[
+			{"id":"25w02a","type":"snapshot","url":"https://piston-meta.mojang.com/v1/packages/bbb/25w02a.json"},
+			{"id":"1.21.4","type":"release","url":"https://piston-meta.mojang.com/v1/packages/aaa/1.21.4.json"}]}`)
+	})
+	meta.HandleFunc("/v1/packages/aaa/1.21.4.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id":"1.21.4","downloads":{"server":{"sha1":%q,"url":"https://piston-data.mojang.com/v1/objects/%s/server.jar"}}}`,
+			hex.EncodeToString(sum[:]), hex.EncodeToString(sum[:]))
+	})
+	metaServer := httptest.NewServer(meta)
+	t.Cleanup(metaServer.Close)
+
+	dataServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/objects/"+hex.EncodeToString(sum[:])+"/server.jar" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, vanillaTestJar)
+	}))
+	t.Cleanup(dataServer.Close)
+
+	t.Setenv("MCINIT_VANILLA_URL", metaServer.URL)
+	t.Setenv("MCINIT_VANILLA_DATA_URL", dataServer.URL)
+
+	p := NewVanillaProvider()
+	p.cache = useTestCache(t)
+	ctx := context.Background()
+
+	versions, err := p.GetAvailableVersions(ctx)
+	if err != nil {
+		t.Fatalf("GetAvailableVersions() error = %v", err)
+	}
+	if want := []string{"1.21.4"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("GetAvailableVersions() = %v, want %v", versions, want)
+	}
+
+	localPath, _, checksum, err := p.DownloadJar(ctx, "1.21.4", "")
+	if err != nil {
+		t.Fatalf("DownloadJar() error = %v", err)
+	}
+	if checksum != hex.EncodeToString(sum[:]) {
+		t.Errorf("DownloadJar() checksum = %s", checksum)
+	}
+	if data, _ := os.ReadFile(localPath); string(data) != vanillaTestJar {
+		t.Errorf("downloaded jar = %q", data)
+	}
+
+	if _, _, _, err := p.DownloadJar(ctx, "1.8.8", ""); err == nil {
+		t.Error("DownloadJar() of an unknown version should fail")
+	}
+}
+
+// newVanillaManifestServer serves a version manifest listing versions out of
+// release order, as happens when Mojang republishes old versions
+func newVanillaManifestServer(t *testing.T) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"latest":{"release":"1.21.4","snapshot":"1.21.5-pre1"},"versions":[
+			{"id":"1.21.3","type":"release","releaseTime":"2024-10-23T12:28:15+00:00"},
+			{"id":"1.21.5-pre1","type":"snapshot","releaseTime":"2025-03-11T12:49:44+00:00"},
+			{"id":"1.21.4","type":"release","releaseTime":"2024-12-03T10:12:57+00:00"},
+			{"id":"1.21.4-rc1","type":"snapshot","releaseTime":"2024-11-28T13:13:19+00:00"},
+			{"id":"25w02a","type":"snapshot","releaseTime":"2025-01-08T13:54:20+00:00"},
+			{"id":"1.14 Pre-Release 1","type":"snapshot","releaseTime":"2019-04-26T13:47:43+00:00"},
+			{"id":"b1.7.3","type":"old_beta","releaseTime":"2011-07-07T22:00:00+00:00"},
+			{"id":"a1.2.6","type":"old_alpha","releaseTime":"2010-12-02T22:00:00+00:00"}]}`)
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("MCINIT_VANILLA_URL", server.URL)
+}
+
+func TestVanillaChannels(t *testing.T) {
+	newVanillaManifestServer(t)
+
+	tests := []struct {
+		channel string
+		want    []string
+		wantErr bool
+	}{
+		{channel: "release", want: []string{"1.21.4", "1.21.3"}},
+		{channel: "snapshot", want: []string{"25w02a"}},
+		{channel: "pre-release", want: []string{"1.21.5-pre1", "1.21.4-rc1", "1.14 Pre-Release 1"}},
+		{channel: "old_beta", want: []string{"b1.7.3"}},
+		{channel: "all", want: []string{
+			"1.21.5-pre1", "25w02a", "1.21.4", "1.21.4-rc1", "1.21.3", "1.14 Pre-Release 1", "b1.7.3", "a1.2.6",
+		}},
+		{channel: "nightly", wantErr: true},
+	}
+
+	p := NewVanillaProvider()
+	for _, tt := range tests {
+		t.Run(tt.channel, func(t *testing.T) {
+			got, err := p.GetVersionsInChannel(context.Background(), tt.channel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetVersionsInChannel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetVersionsInChannel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVanillaResolveVersion(t *testing.T) {
+	newVanillaManifestServer(t)
+
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"latest-release", "1.21.4"},
+		{"latest-snapshot", "1.21.5-pre1"},
+		{"1.20.1", "1.20.1"},
+	}
+
+	p := NewVanillaProvider()
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := p.ResolveVersion(context.Background(), tt.version)
+			if err != nil || got != tt.want {
+				t.Errorf("ResolveVersion() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,6 @@
 package provider
 
 import (
-	"net/http"
-	"time"
-
 	"github.com/jackh54/mcinit/internal/cache"
 )
 
@@ -19,9 +16,8 @@ func NewVelocityProvider() *VelocityProvider {
 		PaperProvider: &PaperProvider{
 			cache:       c,
 			projectName: "velocity",
-			client: &http.Client{
-				Timeout: 30 * time.Second,
-			},
+			api:         PaperAPIFill,
+			client:      newPaperClient("velocity"),
 		},
 	}
 }
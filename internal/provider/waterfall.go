@@ -1,9 +1,6 @@
 package provider
 
 import (
-	"net/http"
-	"time"
-
 	"github.com/jackh54/mcinit/internal/cache"
 )
 
@@ -19,9 +16,8 @@ func NewWaterfallProvider() *WaterfallProvider {
 		PaperProvider: &PaperProvider{
 			cache:       c,
 			projectName: "waterfall",
-			client: &http.Client{
-				Timeout: 30 * time.Second,
-			},
+			api:         PaperAPIV2,
+			client:      newPaperClient("waterfall"),
 		},
 	}
 }
@@ -11,6 +11,10 @@ import (
 // Generator generates startup scripts
 type Generator struct {
 	serverDir string
+
+	// Argument files launched instead of the jar, see SetArgsFiles
+	unixArgsFile    string
+	windowsArgsFile string
 }
 
 // NewGenerator creates a new Generator instance
@@ -20,6 +24,13 @@ func NewGenerator(serverDir string) *Generator {
 	}
 }
 
+// SetArgsFiles makes the scripts launch the argument files written by a
+// server installer, such as Forge's unix_args.txt, instead of a jar
+func (g *Generator) SetArgsFiles(unixArgsFile, windowsArgsFile string) {
+	g.unixArgsFile = unixArgsFile
+	g.windowsArgsFile = windowsArgsFile
+}
+
 // GenerateAll generates all startup scripts (Unix + Windows)
 func (g *Generator) GenerateAll(javaPath, jarPath, xms, xmx string, jvmFlags []string) error {
 	if err := g.GenerateUnix(javaPath, jarPath, xms, xmx, jvmFlags); err != nil {
@@ -35,7 +46,7 @@ func (g *Generator) GenerateAll(javaPath, jarPath, xms, xmx string, jvmFlags []s
 
 // GenerateUnix generates Unix startup script (start.sh)
 func (g *Generator) GenerateUnix(javaPath, jarPath, xms, xmx string, jvmFlags []string) error {
-	script := generateUnixScript(javaPath, jarPath, xms, xmx, jvmFlags)
+	script := generateUnixScript(javaPath, jarPath, g.unixArgsFile, xms, xmx, jvmFlags)
 
 	scriptPath := filepath.Join(g.serverDir, "start.sh")
 	if err := os.WriteFile(scriptPath, []byte(script), 0755); err != nil {
@@ -48,14 +59,14 @@ func (g *Generator) GenerateUnix(javaPath, jarPath, xms, xmx string, jvmFlags []
 // GenerateWindows generates Windows startup scripts (start.ps1 and start.cmd)
 func (g *Generator) GenerateWindows(javaPath, jarPath, xms, xmx string, jvmFlags []string) error {
 	// Generate PowerShell script
-	ps1Script := generateWindowsPowerShellScript(javaPath, jarPath, xms, xmx, jvmFlags)
+	ps1Script := generateWindowsPowerShellScript(javaPath, jarPath, g.windowsArgsFile, xms, xmx, jvmFlags)
 	ps1Path := filepath.Join(g.serverDir, "start.ps1")
 	if err := os.WriteFile(ps1Path, []byte(ps1Script), 0644); err != nil {
 		return fmt.Errorf("failed to write start.ps1: %w", err)
 	}
 
 	// Generate CMD script
-	cmdScript := generateWindowsCMDScript(javaPath, jarPath, xms, xmx, jvmFlags)
+	cmdScript := generateWindowsCMDScript(javaPath, jarPath, g.windowsArgsFile, xms, xmx, jvmFlags)
 	cmdPath := filepath.Join(g.serverDir, "start.cmd")
 	if err := os.WriteFile(cmdPath, []byte(cmdScript), 0644); err != nil {
 		return fmt.Errorf("failed to write start.cmd: %w", err)
@@ -5,11 +5,21 @@ import (
 	"strings"
 )
 
-// generateUnixScript generates a Unix shell script
-func generateUnixScript(javaPath, jarPath, xms, xmx string, jvmFlags []string) string {
+// generateUnixScript generates a Unix shell script. A non-empty argsFile is
+// launched instead of the jar.
+func generateUnixScript(javaPath, jarPath, argsFile, xms, xmx string, jvmFlags []string) string {
 	// Build JVM flags string
 	flagsStr := strings.Join(jvmFlags, " ")
 
+	target := fmt.Sprintf(`# Server jar
+JAR="%s"`, jarPath)
+	launch := `-jar "$JAR"`
+	if argsFile != "" {
+		target = fmt.Sprintf(`# Server arguments file (written by the installer)
+ARGS_FILE="%s"`, argsFile)
+		launch = `"@$ARGS_FILE"`
+	}
+
 	script := fmt.Sprintf(`#!/bin/bash
 # Generated by mcinit
 # Minecraft Server Startup Script
@@ -20,8 +30,7 @@ cd "$(dirname "$0")"
 # Java executable
 JAVA="%s"
 
-# Server jar
-JAR="%s"
+%s
 
 # JVM Memory settings
 XMS="%s"
@@ -32,8 +41,8 @@ JVM_FLAGS="%s"
 
 # Start server
 echo "Starting Minecraft server..."
-exec "$JAVA" $JVM_FLAGS -jar "$JAR" nogui
-`, javaPath, jarPath, xms, xmx, flagsStr)
+exec "$JAVA" $JVM_FLAGS %s nogui
+`, javaPath, target, xms, xmx, flagsStr, launch)
 
 	return script
 }
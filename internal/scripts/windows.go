@@ -5,11 +5,21 @@ import (
 	"strings"
 )
 
-// generateWindowsPowerShellScript generates a PowerShell script
-func generateWindowsPowerShellScript(javaPath, jarPath, xms, xmx string, jvmFlags []string) string {
+// generateWindowsPowerShellScript generates a PowerShell script. A non-empty
+// argsFile is launched instead of the jar.
+func generateWindowsPowerShellScript(javaPath, jarPath, argsFile, xms, xmx string, jvmFlags []string) string {
 	// Build JVM flags string
 	flagsStr := strings.Join(jvmFlags, " ")
 
+	target := fmt.Sprintf(`# Server jar
+$JAR = "%s"`, jarPath)
+	launch := `-jar $JAR`
+	if argsFile != "" {
+		target = fmt.Sprintf(`# Server arguments file (written by the installer)
+$ARGS_FILE = "%s"`, argsFile)
+		launch = `"@$ARGS_FILE"`
+	}
+
 	script := fmt.Sprintf(`# Generated by mcinit
 # Minecraft Server Startup Script
 
@@ -19,8 +29,7 @@ Set-Location $PSScriptRoot
 # Java executable
 $JAVA = "%s"
 
-# Server jar
-$JAR = "%s"
+%s
 
 # JVM Memory settings
 $XMS = "%s"
@@ -31,17 +40,27 @@ $JVM_FLAGS = "%s"
 
 # Start server
 Write-Host "Starting Minecraft server..."
-& $JAVA $JVM_FLAGS.Split(" ") -jar $JAR nogui
-`, javaPath, jarPath, xms, xmx, flagsStr)
+& $JAVA $JVM_FLAGS.Split(" ") %s nogui
+`, javaPath, target, xms, xmx, flagsStr, launch)
 
 	return script
 }
 
-// generateWindowsCMDScript generates a CMD batch script
-func generateWindowsCMDScript(javaPath, jarPath, xms, xmx string, jvmFlags []string) string {
+// generateWindowsCMDScript generates a CMD batch script. A non-empty argsFile
+// is launched instead of the jar.
+func generateWindowsCMDScript(javaPath, jarPath, argsFile, xms, xmx string, jvmFlags []string) string {
 	// Build JVM flags string
 	flagsStr := strings.Join(jvmFlags, " ")
 
+	target := fmt.Sprintf(`REM Server jar
+set JAR=%s`, jarPath)
+	launch := `-jar "%JAR%"`
+	if argsFile != "" {
+		target = fmt.Sprintf(`REM Server arguments file (written by the installer)
+set ARGS_FILE=%s`, argsFile)
+		launch = `@%ARGS_FILE%`
+	}
+
 	script := fmt.Sprintf(`@echo off
 REM Generated by mcinit
 REM Minecraft Server Startup Script
@@ -52,8 +71,7 @@ cd /d %%~dp0
 REM Java executable
 set JAVA=%s
 
-REM Server jar
-set JAR=%s
+%s
 
 REM JVM Memory settings
 set XMS=%s
@@ -64,10 +82,9 @@ set JVM_FLAGS=%s
 
 REM Start server
 echo Starting Minecraft server...
-"%%JAVA%%" %%JVM_FLAGS%% -jar "%%JAR%%" nogui
+"%%JAVA%%" %%JVM_FLAGS%% %s nogui
 pause
-`, javaPath, jarPath, xms, xmx, flagsStr)
+`, javaPath, target, xms, xmx, flagsStr, launch)
 
 	return script
 }
-
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Console connection modes, sent by the client as the first line
+const (
+	ConsoleModeAttach = "attach" // replay recent output, then stream
+	ConsoleModeExec   = "exec"   // stream output from now on
+)
+
+const (
+	consoleBacklogLines = 200
+	consoleClientBuffer = 512
+	maxSocketPathLength = 100 // sun_path is 104-108 bytes depending on platform
+)
+
+// ConsoleSocketPath returns the path of the console socket served by the supervisor
+func ConsoleSocketPath(serverDir string) string {
+	return filepath.Join(serverDir, ".mcinit", "console.sock")
+}
+
+// socketAddress shortens a socket path that is too long for a Unix socket
+// address by making it relative to the working directory
+func socketAddress(path string) string {
+	if len(path) <= maxSocketPathLength {
+		return path
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(cwd, path); err == nil && len(rel) < len(path) {
+		return rel
+	}
+	return path
+}
+
+// consoleHub fans out server console output to attached clients and
+// forwards their input to the server
+type consoleHub struct {
+	listener net.Listener
+	path     string
+	input    func(line string) error
+
+	mu      sync.Mutex
+	backlog []string
+	clients map[*consoleConn]struct{}
+}
+
+// consoleConn is a single attached client
+type consoleConn struct {
+	conn  net.Conn
+	lines chan string
+}
+
+// listenConsole starts serving the console socket at path
+func listenConsole(path string, input func(string) error) (*consoleHub, error) {
+	// A socket left behind by a dead supervisor blocks Listen
+	_ = os.Remove(path)
+
+	listener, err := net.Listen("unix", socketAddress(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on console socket: %w", err)
+	}
+
+	hub := &consoleHub{
+		listener: listener,
+		path:     path,
+		input:    input,
+		clients:  make(map[*consoleConn]struct{}),
+	}
+	go hub.serve()
+
+	return hub, nil
+}
+
+// broadcast sends an output line to all attached clients
+func (h *consoleHub) broadcast(line string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.backlog = append(h.backlog, line)
+	if len(h.backlog) > consoleBacklogLines {
+		h.backlog = h.backlog[len(h.backlog)-consoleBacklogLines:]
+	}
+
+	for client := range h.clients {
+		select {
+		case client.lines <- line:
+		default:
+			// Drop clients that cannot keep up rather than stalling the server
+			h.removeLocked(client)
+		}
+	}
+}
+
+// Close stops serving and disconnects all clients
+func (h *consoleHub) Close() error {
+	err := h.listener.Close()
+
+	h.mu.Lock()
+	for client := range h.clients {
+		h.removeLocked(client)
+	}
+	h.mu.Unlock()
+
+	_ = os.Remove(h.path)
+	return err
+}
+
+// serve accepts client connections until the listener is closed
+func (h *consoleHub) serve() {
+	for {
+		conn, err := h.listener.Accept()
+		if err != nil {
+			return
+		}
+		go h.handle(conn)
+	}
+}
+
+// handle registers a client, then forwards its lines to the server console
+func (h *consoleHub) handle(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	mode, err := reader.ReadString('\n')
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	client := &consoleConn{
+		conn:  conn,
+		lines: make(chan string, consoleClientBuffer),
+	}
+
+	h.mu.Lock()
+	if strings.TrimSpace(mode) == ConsoleModeAttach {
+		for _, line := range h.backlog {
+			client.lines <- line
+		}
+	}
+	h.clients[client] = struct{}{}
+	h.mu.Unlock()
+
+	go client.writeLoop()
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		if err := h.input(scanner.Text()); err != nil {
+			break
+		}
+	}
+
+	h.mu.Lock()
+	h.removeLocked(client)
+	h.mu.Unlock()
+}
+
+// removeLocked disconnects a client. Caller must hold h.mu.
+func (h *consoleHub) removeLocked(client *consoleConn) {
+	if _, ok := h.clients[client]; !ok {
+		return
+	}
+	delete(h.clients, client)
+	close(client.lines)
+}
+
+// writeLoop writes queued lines to the client until the queue is closed
+func (c *consoleConn) writeLoop() {
+	defer func() { _ = c.conn.Close() }()
+
+	writer := bufio.NewWriter(c.conn)
+	for line := range c.lines {
+		if _, err := writer.WriteString(line + "\n"); err != nil {
+			return
+		}
+		// Flush once the queue is drained to batch bursts of output
+		if len(c.lines) == 0 {
+			if err := writer.Flush(); err != nil {
+				return
+			}
+		}
+	}
+	_ = writer.Flush()
+}
+
+// ConsoleClient is a connection to a supervisor's console socket
+type ConsoleClient struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+}
+
+// DialConsole connects to the console socket of a supervised server
+func DialConsole(serverDir, mode string) (*ConsoleClient, error) {
+	path := ConsoleSocketPath(serverDir)
+
+	conn, err := net.DialTimeout("unix", socketAddress(path), 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to server console: %w", err)
+	}
+
+	if _, err := fmt.Fprintf(conn, "%s\n", mode); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to connect to server console: %w", err)
+	}
+
+	return &ConsoleClient{
+		conn:    conn,
+		scanner: bufio.NewScanner(conn),
+	}, nil
+}
+
+// Send writes a line to the server console
+func (c *ConsoleClient) Send(line string) error {
+	_, err := fmt.Fprintf(c.conn, "%s\n", line)
+	return err
+}
+
+// ReadLine reads the next line of console output
+func (c *ConsoleClient) ReadLine() (string, error) {
+	if c.scanner.Scan() {
+		return c.scanner.Text(), nil
+	}
+	if err := c.scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", io.EOF
+}
+
+// SetReadDeadline sets the deadline for ReadLine
+func (c *ConsoleClient) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// Close closes the connection, leaving the server running
+func (c *ConsoleClient) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConsoleAttachAndInput(t *testing.T) {
+	serverDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(serverDir, ".mcinit"), 0755); err != nil {
+		t.Fatalf("Failed to create .mcinit: %v", err)
+	}
+
+	input := make(chan string, 10)
+	hub, err := listenConsole(ConsoleSocketPath(serverDir), func(line string) error {
+		input <- line
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("listenConsole() error = %v", err)
+	}
+	defer func() { _ = hub.Close() }()
+
+	// Output produced before attaching is replayed
+	hub.broadcast("[Server thread/INFO]: Starting minecraft server")
+
+	client, err := DialConsole(serverDir, ConsoleModeAttach)
+	if err != nil {
+		t.Fatalf("DialConsole() error = %v", err)
+	}
+	defer func() { _ = client.Close() }()
+	_ = client.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	line, err := client.ReadLine()
+	if err != nil {
+		t.Fatalf("ReadLine() error = %v", err)
+	}
+	if line != "[Server thread/INFO]: Starting minecraft server" {
+		t.Errorf("ReadLine() = %q, want backlog line", line)
+	}
+
+	if err := client.Send("say hello"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	select {
+	case got := <-input:
+		if got != "say hello" {
+			t.Errorf("Server received %q, want %q", got, "say hello")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Server did not receive input")
+	}
+
+	hub.broadcast("[Server thread/INFO]: Hello")
+	line, err = client.ReadLine()
+	if err != nil {
+		t.Fatalf("ReadLine() error = %v", err)
+	}
+	if line != "[Server thread/INFO]: Hello" {
+		t.Errorf("ReadLine() = %q, want live line", line)
+	}
+}
+
+func TestConsoleExecSkipsBacklog(t *testing.T) {
+	serverDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(serverDir, ".mcinit"), 0755); err != nil {
+		t.Fatalf("Failed to create .mcinit: %v", err)
+	}
+
+	hub, err := listenConsole(ConsoleSocketPath(serverDir), func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("listenConsole() error = %v", err)
+	}
+	defer func() { _ = hub.Close() }()
+
+	hub.broadcast("old output")
+
+	client, err := DialConsole(serverDir, ConsoleModeExec)
+	if err != nil {
+		t.Fatalf("DialConsole() error = %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	// Wait for the hub to register the client before producing output
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		hub.mu.Lock()
+		registered := len(hub.clients) == 1
+		hub.mu.Unlock()
+		if registered || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	hub.broadcast("new output")
+
+	_ = client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := client.ReadLine()
+	if err != nil {
+		t.Fatalf("ReadLine() error = %v", err)
+	}
+	if line != "new output" {
+		t.Errorf("ReadLine() = %q, want %q", line, "new output")
+	}
+}
+
+func TestConsoleCloseRemovesSocket(t *testing.T) {
+	serverDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(serverDir, ".mcinit"), 0755); err != nil {
+		t.Fatalf("Failed to create .mcinit: %v", err)
+	}
+
+	path := ConsoleSocketPath(serverDir)
+	hub, err := listenConsole(path, func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("listenConsole() error = %v", err)
+	}
+
+	if err := hub.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Console socket should be removed after Close()")
+	}
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackh54/mcinit/internal/utils"
+)
+
+// consoleIdleTimeout is how long Exec waits for more console output before
+// considering a command's response complete
+const consoleIdleTimeout = 500 * time.Millisecond
+
+// ExecResult is the outcome of a command sent with Exec
+type ExecResult struct {
+	Command  string `json:"command"`
+	Response string `json:"response"`
+	Source   string `json:"source"` // "rcon", or "console" for a best-effort response
+}
+
+// Exec sends a single command to the running server and returns its
+// response. RCON is used when enabled, otherwise the supervisor console.
+func (m *Manager) Exec(command string, timeout time.Duration) (*ExecResult, error) {
+	if m.config == nil {
+		if err := m.LoadConfig(); err != nil {
+			return nil, err
+		}
+	}
+
+	if !m.process.IsRunning() {
+		return nil, fmt.Errorf("server is not running")
+	}
+
+	hasConsole := utils.PathExists(ConsoleSocketPath(m.serverDir))
+
+	if rcon := m.RCONClient(); rcon != nil {
+		rcon.SetTimeout(timeout)
+		defer func() { _ = rcon.Close() }()
+
+		response, err := rcon.SendCommand(command)
+		if err == nil {
+			return &ExecResult{Command: command, Response: response, Source: "rcon"}, nil
+		}
+		if !hasConsole {
+			return nil, fmt.Errorf("failed to send command via RCON: %w", err)
+		}
+		// RCON may still be starting up, fall back to the console
+	}
+
+	if !hasConsole {
+		return nil, fmt.Errorf("no way to reach the server: RCON is disabled and the server was not started with --background")
+	}
+
+	response, err := m.execConsole(command, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExecResult{Command: command, Response: response, Source: "console"}, nil
+}
+
+// execConsole sends a command through the console socket and collects the
+// output that follows it. The console has no request/response framing, so
+// output is collected until it goes quiet, and any log lines printed
+// meanwhile, such as players joining, end up in the response.
+func (m *Manager) execConsole(command string, timeout time.Duration) (string, error) {
+	client, err := DialConsole(m.serverDir, ConsoleModeExec)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = client.Close() }()
+
+	if err := client.Send(command); err != nil {
+		return "", fmt.Errorf("failed to send command via console: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	var lines []string
+	for {
+		idle := time.Now().Add(consoleIdleTimeout)
+		if idle.After(deadline) {
+			idle = deadline
+		}
+		_ = client.SetReadDeadline(idle)
+
+		line, err := client.ReadLine()
+		if err != nil {
+			// Quiet period or console closed, either way we are done
+			break
+		}
+		lines = append(lines, line)
+
+		if time.Now().After(deadline) {
+			break
+		}
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
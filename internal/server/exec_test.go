@@ -0,0 +1,92 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jackh54/mcinit/internal/config"
+)
+
+// newTestManager creates a Manager for a server directory whose state file
+// points at the test process, so it counts as running
+func newTestManager(t *testing.T, mutate func(cfg *config.Config)) *Manager {
+	t.Helper()
+
+	serverDir := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Server.MinecraftVersion = "1.21.4"
+	mutate(cfg)
+	if err := config.Save(cfg, filepath.Join(serverDir, "mcinit.json")); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	state := &State{PID: os.Getpid(), StartTime: time.Now(), Status: StatusRunning}
+	if err := NewStateFile(serverDir).Save(state); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	mgr, err := NewManager(serverDir)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	return mgr
+}
+
+func TestExecViaRCON(t *testing.T) {
+	srv := newFakeRCONServer(t, "secret", func(cmd string) string {
+		return "Made dev a server operator"
+	})
+
+	mgr := newTestManager(t, func(cfg *config.Config) {
+		cfg.RCON.Enabled = true
+		cfg.RCON.Port = srv.port()
+		cfg.RCON.Password = "secret"
+	})
+
+	result, err := mgr.Exec("op dev", 5*time.Second)
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if result.Source != "rcon" {
+		t.Errorf("Exec() source = %s, want rcon", result.Source)
+	}
+	if result.Response != "Made dev a server operator" {
+		t.Errorf("Exec() response = %q", result.Response)
+	}
+}
+
+func TestExecViaConsole(t *testing.T) {
+	mgr := newTestManager(t, func(cfg *config.Config) {})
+
+	// Echo every command back like a server console would
+	var hub *consoleHub
+	hub, err := listenConsole(ConsoleSocketPath(mgr.serverDir), func(line string) error {
+		go hub.broadcast("> " + line)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("listenConsole() error = %v", err)
+	}
+	defer func() { _ = hub.Close() }()
+
+	result, err := mgr.Exec("save-all", 5*time.Second)
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if result.Source != "console" {
+		t.Errorf("Exec() source = %s, want console", result.Source)
+	}
+	if result.Response != "> save-all" {
+		t.Errorf("Exec() response = %q, want %q", result.Response, "> save-all")
+	}
+}
+
+func TestExecUnreachable(t *testing.T) {
+	mgr := newTestManager(t, func(cfg *config.Config) {})
+
+	if _, err := mgr.Exec("list", time.Second); err == nil {
+		t.Error("Exec() should fail without RCON or a console socket")
+	}
+}
@@ -0,0 +1,37 @@
+package server
+
+import "errors"
+
+// errProcIdentityUnsupported is returned where process identities cannot be read
+var errProcIdentityUnsupported = errors.New("process identity is not supported on this platform")
+
+// procIdentity tells a process apart from a later one that reuses its PID
+type procIdentity struct {
+	StartTime uint64 // clock ticks since boot
+	Cmdline   string
+}
+
+// processMatches checks that pid is alive and is still the process with the
+// recorded start time and command line. Zero values skip the check, for state
+// written by an older mcinit or on platforms without process identities.
+func processMatches(pid int, startTime uint64, cmdline string) bool {
+	if pid <= 0 || !processAlive(pid) {
+		return false
+	}
+	if startTime == 0 && cmdline == "" {
+		return true
+	}
+
+	identity, err := readProcIdentity(pid)
+	if err != nil {
+		return errors.Is(err, errProcIdentityUnsupported)
+	}
+
+	if startTime != 0 && identity.StartTime != startTime {
+		return false
+	}
+	if cmdline != "" && identity.Cmdline != cmdline {
+		return false
+	}
+	return true
+}
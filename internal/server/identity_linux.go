@@ -0,0 +1,76 @@
+//go:build linux
+
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readProcIdentity reads the start time and command line of a process from /proc
+func readProcIdentity(pid int) (*procIdentity, error) {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read process stat: %w", err)
+	}
+
+	startTime, err := parseStatStartTime(string(stat))
+	if err != nil {
+		return nil, err
+	}
+
+	// A zombie keeps its PID until reaped, but is no longer running
+	if state, _ := statField(string(stat), 3); state == "Z" {
+		return nil, fmt.Errorf("process %d has exited", pid)
+	}
+
+	cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read process command line: %w", err)
+	}
+
+	return &procIdentity{
+		StartTime: startTime,
+		Cmdline:   strings.TrimSpace(string(bytes.ReplaceAll(cmdline, []byte{0}, []byte{' '}))),
+	}, nil
+}
+
+// statField returns field n, numbered from 1 as in proc(5), of
+// /proc/<pid>/stat. Only fields after the command name are supported.
+func statField(stat string, n int) (string, error) {
+	// The command name in field 2 may contain spaces and parentheses, so
+	// count fields from the last closing parenthesis
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 || n < 3 {
+		return "", fmt.Errorf("malformed process stat")
+	}
+
+	// Fields after the command name start with field 3
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) <= n-3 {
+		return "", fmt.Errorf("malformed process stat")
+	}
+	return fields[n-3], nil
+}
+
+// statUint parses numeric field n of /proc/<pid>/stat
+func statUint(stat string, n int) (uint64, error) {
+	field, err := statField(stat, n)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := strconv.ParseUint(field, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse process stat field %d: %w", n, err)
+	}
+	return value, nil
+}
+
+// parseStatStartTime extracts the start time, field 22, from /proc/<pid>/stat
+func parseStatStartTime(stat string) (uint64, error) {
+	return statUint(stat, 22)
+}
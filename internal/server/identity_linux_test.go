@@ -0,0 +1,126 @@
+//go:build linux
+
+package server
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseStatStartTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		stat    string
+		want    uint64
+		wantErr bool
+	}{
+		{
+			"java",
+			"1234 (java) S 1 1234 1234 0 -1 4194560 5000 0 0 0 100 20 0 0 20 0 42 0 987654 5000000000 100000",
+			987654, false,
+		},
+		{
+			"command with spaces and parentheses",
+			"1234 (my (odd) cmd) S 1 1234 1234 0 -1 4194560 5000 0 0 0 100 20 0 0 20 0 42 0 555 5000000000 100000",
+			555, false,
+		},
+		{"truncated", "1234 (java) S 1 1234", 0, true},
+		{"malformed", "garbage", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStatStartTime(tt.stat)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStatStartTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseStatStartTime() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadProcIdentity(t *testing.T) {
+	identity, err := readProcIdentity(os.Getpid())
+	if err != nil {
+		t.Fatalf("readProcIdentity() error = %v", err)
+	}
+	if identity.StartTime == 0 {
+		t.Error("readProcIdentity() start time should not be zero")
+	}
+	if !strings.Contains(identity.Cmdline, os.Args[0]) {
+		t.Errorf("readProcIdentity() cmdline = %q, want it to contain %q", identity.Cmdline, os.Args[0])
+	}
+
+	if !processMatches(os.Getpid(), identity.StartTime, identity.Cmdline) {
+		t.Error("processMatches() should match the current process")
+	}
+	if processMatches(os.Getpid(), identity.StartTime+1, identity.Cmdline) {
+		t.Error("processMatches() should reject a different start time")
+	}
+	if processMatches(os.Getpid(), identity.StartTime, "java -jar server.jar nogui") {
+		t.Error("processMatches() should reject a different command line")
+	}
+}
+
+func TestIsRunningCleansReusedPID(t *testing.T) {
+	identity, err := readProcIdentity(os.Getpid())
+	if err != nil {
+		t.Fatalf("readProcIdentity() error = %v", err)
+	}
+
+	// The PID is alive but belongs to a process started after the server
+	stateFile := NewStateFile(t.TempDir())
+	state := &State{
+		PID:           os.Getpid(),
+		ProcStartTime: identity.StartTime - 1,
+		Cmdline:       "java -jar server.jar nogui",
+		StartTime:     time.Now(),
+		Status:        StatusRunning,
+	}
+	if err := stateFile.Save(state); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	process := NewProcess(stateFile.serverDir)
+	if process.IsRunning() {
+		t.Error("IsRunning() should not trust a reused PID")
+	}
+	if stateFile.Exists() {
+		t.Error("Stale state files should be removed")
+	}
+	if err := process.Stop(true); err == nil {
+		t.Error("Stop() should refuse to signal a process that is not the server")
+	}
+}
+
+func TestIsRunningKeepsStateWhileSupervisorAlive(t *testing.T) {
+	identity, err := readProcIdentity(os.Getpid())
+	if err != nil {
+		t.Fatalf("readProcIdentity() error = %v", err)
+	}
+
+	// The server is gone, but its supervisor has yet to record the exit
+	stateFile := NewStateFile(t.TempDir())
+	state := &State{
+		PID:                 os.Getpid(),
+		ProcStartTime:       identity.StartTime - 1,
+		SupervisorPID:       os.Getpid(),
+		SupervisorStartTime: identity.StartTime,
+		StartTime:           time.Now(),
+		Status:              StatusRunning,
+	}
+	if err := stateFile.Save(state); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if NewProcess(stateFile.serverDir).IsRunning() {
+		t.Error("IsRunning() should not trust a reused PID")
+	}
+	if !stateFile.Exists() {
+		t.Error("State should be kept while the supervisor is alive")
+	}
+}
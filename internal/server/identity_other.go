@@ -0,0 +1,8 @@
+//go:build !linux
+
+package server
+
+// readProcIdentity is only implemented on Linux
+func readProcIdentity(pid int) (*procIdentity, error) {
+	return nil, errProcIdentityUnsupported
+}
@@ -1,8 +1,15 @@
 package server
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"syscall"
+	"time"
 
 	"github.com/jackh54/mcinit/internal/config"
 	"github.com/jackh54/mcinit/internal/java"
@@ -38,11 +45,128 @@ func (m *Manager) LoadConfig() error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 	m.config = cfg
+	m.process.SetStopOptions(m.stopOptions())
+	m.process.SetServerType(cfg.Server.Type)
 	return nil
 }
 
-// Start starts the server
+// Start starts the server. In background mode the server is handed to a
+// detached supervisor process so it survives the CLI exiting.
 func (m *Manager) Start(background bool, extraArgs string) error {
+	if err := m.prepareStart(); err != nil {
+		return err
+	}
+
+	if background {
+		_, err := spawnSupervisor(m.serverDir, extraArgs)
+		return err
+	}
+
+	javaPath, jvmArgs, launchArgs, err := m.launchCommand(extraArgs)
+	if err != nil {
+		return err
+	}
+
+	return m.runWithRestarts(javaPath, jvmArgs, launchArgs, RunOptions{
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+		Stdin:  os.Stdin,
+	})
+}
+
+// Supervise runs the server as the supervisor started by a background Start.
+// Console output goes to the console log instead of the terminal.
+func (m *Manager) Supervise(extraArgs string) error {
+	if err := m.prepareStart(); err != nil {
+		return err
+	}
+
+	javaPath, jvmArgs, launchArgs, err := m.launchCommand(extraArgs)
+	if err != nil {
+		return err
+	}
+
+	// A fresh console log for every run, like logs/latest.log
+	logFile, err := os.Create(ConsoleLogPath(m.serverDir))
+	if err != nil {
+		return fmt.Errorf("failed to create console log: %w", err)
+	}
+	defer func() { _ = logFile.Close() }()
+
+	fmt.Printf("[%s] Supervisor %d starting server\n", time.Now().Format(time.RFC3339), os.Getpid())
+
+	err = m.runWithRestarts(javaPath, jvmArgs, launchArgs, RunOptions{
+		Stdout:        logFile,
+		Stderr:        logFile,
+		ConsoleSocket: ConsoleSocketPath(m.serverDir),
+	})
+
+	fmt.Printf("[%s] Server exited: %v\n", time.Now().Format(time.RFC3339), err)
+
+	// A non-zero exit is already recorded in the state file
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return nil
+	}
+	return err
+}
+
+// runWithRestarts runs the server and restarts it according to the
+// configured restart policy until it stays down
+func (m *Manager) runWithRestarts(javaPath string, jvmArgs, launchArgs []string, opts RunOptions) error {
+	policy := NewRestartPolicy(m.config.Restart)
+
+	// Listen for shutdown requests for the whole lifetime, so a signal
+	// between two runs cancels the restart instead of killing us
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	for {
+		err := m.process.Run(javaPath, jvmArgs, launchArgs, opts)
+
+		state, stateErr := m.process.GetState()
+		if stateErr != nil {
+			return err
+		}
+
+		// A signal during the run already stopped the server
+		select {
+		case <-sigChan:
+			return err
+		default:
+		}
+
+		now := time.Now()
+		delay, ok := policy.Next(state, now)
+		if !ok {
+			if state.LastExit != nil && state.LastExit.Crashed && policy.Policy != config.RestartNever {
+				fmt.Printf("Server crashed, not restarting (restart limit reached)\n")
+			}
+			return err
+		}
+
+		recordRestart(state, now, delay)
+		if saveErr := m.process.stateFile.Save(state); saveErr != nil {
+			return fmt.Errorf("failed to save state: %w", saveErr)
+		}
+
+		fmt.Printf("Server exited (%s), restarting in %s (restart #%d)\n",
+			state.LastExit.Summary(), delay, state.Restarts)
+
+		select {
+		case <-time.After(delay):
+		case <-sigChan:
+			fmt.Printf("Restart cancelled\n")
+			state.Status = StatusStopped
+			_ = m.process.stateFile.Save(state)
+			return err
+		}
+	}
+}
+
+// prepareStart loads the config and checks the server is not already running
+func (m *Manager) prepareStart() error {
 	// Load config if not already loaded
 	if m.config == nil {
 		if err := m.LoadConfig(); err != nil {
@@ -54,31 +178,62 @@ func (m *Manager) Start(background bool, extraArgs string) error {
 	if m.process.IsRunning() {
 		return fmt.Errorf("server is already running")
 	}
+	if m.process.IsRestarting() {
+		return fmt.Errorf("server is about to be restarted by its supervisor (use 'mcinit stop' to cancel)")
+	}
+
+	return nil
+}
 
+// launchCommand resolves the Java executable, JVM arguments and the
+// arguments that launch the server
+func (m *Manager) launchCommand(extraArgs string) (string, []string, []string, error) {
 	// Resolve Java path
 	javaPath, err := m.resolveJavaPath()
 	if err != nil {
-		return fmt.Errorf("failed to resolve Java path: %w", err)
+		return "", nil, nil, fmt.Errorf("failed to resolve Java path: %w", err)
 	}
 
 	// Build JVM arguments
 	jvmArgs, err := m.buildJVMArgs(extraArgs)
 	if err != nil {
-		return fmt.Errorf("failed to build JVM arguments: %w", err)
+		return "", nil, nil, fmt.Errorf("failed to build JVM arguments: %w", err)
+	}
+
+	launchArgs, err := m.launchArgs(runtime.GOOS)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	return javaPath, jvmArgs, launchArgs, nil
+}
+
+// launchArgs returns the arguments that launch the server: the argument file
+// written by an installer such as Forge's, or the server jar
+func (m *Manager) launchArgs(goos string) ([]string, error) {
+	if argsFile := m.config.Server.PlatformArgsFile(goos); argsFile != "" {
+		// The argument file refers to libraries relative to the server directory
+		if !utils.PathExists(filepath.Join(m.serverDir, filepath.FromSlash(argsFile))) {
+			return nil, fmt.Errorf("server argument file not found: %s", argsFile)
+		}
+		return []string{"@" + argsFile}, nil
 	}
 
-	// Get jar path
 	jarPath := filepath.Join(m.serverDir, m.config.Server.JarPath)
 	if !utils.PathExists(jarPath) {
-		return fmt.Errorf("server jar not found: %s", jarPath)
+		return nil, fmt.Errorf("server jar not found: %s", jarPath)
 	}
 
-	// Start the process
-	return m.process.Start(javaPath, jarPath, jvmArgs, background)
+	return []string{"-jar", jarPath}, nil
 }
 
 // Stop stops the server
 func (m *Manager) Stop(force bool) error {
+	// Without a config the server can still be stopped with signals
+	if m.config == nil {
+		_ = m.LoadConfig()
+	}
+
 	return m.process.Stop(force)
 }
 
@@ -106,11 +261,66 @@ func (m *Manager) IsRunning() bool {
 	return m.process.IsRunning()
 }
 
+// IsRestarting checks if the server is down and waiting to be restarted
+func (m *Manager) IsRestarting() bool {
+	return m.process.IsRestarting()
+}
+
+// AttachConsole connects to the console of a server running under a supervisor
+func (m *Manager) AttachConsole(mode string) (*ConsoleClient, error) {
+	if !m.process.IsRunning() {
+		return nil, fmt.Errorf("server is not running")
+	}
+
+	if !utils.PathExists(ConsoleSocketPath(m.serverDir)) {
+		return nil, fmt.Errorf("server console is not attachable (start the server with --background)")
+	}
+
+	return DialConsole(m.serverDir, mode)
+}
+
 // GetStatus returns the server status
 func (m *Manager) GetStatus() (*State, error) {
 	return m.process.GetState()
 }
 
+// RCONClient returns an RCON client for the server, or nil if RCON is disabled
+func (m *Manager) RCONClient() *RCONClient {
+	if m.config == nil || !m.config.RCON.Enabled || m.config.RCON.Password == "" {
+		return nil
+	}
+
+	return NewRCONClient(m.config.RCON.Host, m.config.RCON.Port, m.config.RCON.Password)
+}
+
+// stopOptions builds the shutdown options from the config
+func (m *Manager) stopOptions() StopOptions {
+	opts := DefaultStopOptions()
+	opts.RCON = m.RCONClient()
+
+	shutdown := m.config.Shutdown
+	if shutdown.RCONTimeout != nil {
+		opts.RCONTimeout = time.Duration(*shutdown.RCONTimeout) * time.Second
+	}
+	if shutdown.StdinTimeout != nil {
+		opts.StdinTimeout = time.Duration(*shutdown.StdinTimeout) * time.Second
+	}
+	if shutdown.TermTimeout != nil {
+		opts.TermTimeout = time.Duration(*shutdown.TermTimeout) * time.Second
+	}
+	return opts
+}
+
+// JavaPath returns the Java executable the server runs with
+func (m *Manager) JavaPath() (string, error) {
+	if m.config == nil {
+		if err := m.LoadConfig(); err != nil {
+			return "", err
+		}
+	}
+	return m.resolveJavaPath()
+}
+
 // resolveJavaPath resolves the Java executable path
 func (m *Manager) resolveJavaPath() (string, error) {
 	if m.config.Java.Path != "" && m.config.Java.Path != "auto" {
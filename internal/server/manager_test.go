@@ -0,0 +1,61 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jackh54/mcinit/internal/config"
+)
+
+func TestLaunchArgs(t *testing.T) {
+	serverDir := t.TempDir()
+	argsDir := filepath.Join(serverDir, "libraries", "net", "minecraftforge", "forge", "1.21.4-54.0.26")
+	if err := os.MkdirAll(argsDir, 0755); err != nil {
+		t.Fatalf("Failed to create libraries: %v", err)
+	}
+	for _, name := range []string{"unix_args.txt", "win_args.txt"} {
+		if err := os.WriteFile(filepath.Join(argsDir, name), nil, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(serverDir, "server.jar"), nil, 0644); err != nil {
+		t.Fatalf("Failed to write server.jar: %v", err)
+	}
+
+	argsFile := "libraries/net/minecraftforge/forge/1.21.4-54.0.26/unix_args.txt"
+
+	tests := []struct {
+		name    string
+		server  config.ServerConfig
+		goos    string
+		want    []string
+		wantErr bool
+	}{
+		{"jar", config.ServerConfig{JarPath: "server.jar"}, "linux", []string{"-jar", filepath.Join(serverDir, "server.jar")}, false},
+		{"missing jar", config.ServerConfig{JarPath: "missing.jar"}, "linux", nil, true},
+		{"unix argument file", config.ServerConfig{ArgsFile: argsFile}, "linux", []string{"@" + argsFile}, false},
+		{"windows argument file", config.ServerConfig{ArgsFile: argsFile}, "windows",
+			[]string{"@libraries/net/minecraftforge/forge/1.21.4-54.0.26/win_args.txt"}, false},
+		{"missing argument file", config.ServerConfig{ArgsFile: "libraries/unix_args.txt"}, "linux", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, err := NewManager(serverDir)
+			if err != nil {
+				t.Fatalf("NewManager() error = %v", err)
+			}
+			mgr.config = &config.Config{Server: tt.server}
+
+			got, err := mgr.launchArgs(tt.goos)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("launchArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("launchArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
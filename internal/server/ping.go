@@ -0,0 +1,302 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Server List Ping packet IDs
+const (
+	slpHandshakeID = 0x00
+	slpStatusID    = 0x00
+	slpPingID      = 0x01
+)
+
+const (
+	slpProtocolVersion = -1 // any version, the status request does not depend on it
+	slpNextStateStatus = 1
+	slpMaxPacketLength = 1 << 21
+	slpDefaultTimeout  = 5 * time.Second
+)
+
+// formattingCodePattern matches legacy § color and formatting codes
+var formattingCodePattern = regexp.MustCompile(`§[0-9a-fk-orA-FK-OR]`)
+
+// PingResult is the status reported by a server through Server List Ping
+type PingResult struct {
+	Version       string        `json:"version"`
+	Protocol      int           `json:"protocol"`
+	MOTD          string        `json:"motd"`
+	OnlinePlayers int           `json:"onlinePlayers"`
+	MaxPlayers    int           `json:"maxPlayers"`
+	Players       []string      `json:"players,omitempty"` // sample sent by the server
+	Latency       time.Duration `json:"latency"`
+}
+
+// slpStatus is the JSON status response
+type slpStatus struct {
+	Version struct {
+		Name     string `json:"name"`
+		Protocol int    `json:"protocol"`
+	} `json:"version"`
+	Players struct {
+		Max    int `json:"max"`
+		Online int `json:"online"`
+		Sample []struct {
+			Name string `json:"name"`
+		} `json:"sample"`
+	} `json:"players"`
+	Description json.RawMessage `json:"description"`
+}
+
+// Ping queries a server's status using the Server List Ping protocol
+func Ping(host string, port int, timeout time.Duration) (*PingResult, error) {
+	if timeout <= 0 {
+		timeout = slpDefaultTimeout
+	}
+
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, fmt.Errorf("failed to set deadline: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	started := time.Now()
+
+	// Handshake followed by a status request
+	handshake := new(bytes.Buffer)
+	writeVarInt(handshake, slpProtocolVersion)
+	writeString(handshake, host)
+	_ = binary.Write(handshake, binary.BigEndian, uint16(port))
+	writeVarInt(handshake, slpNextStateStatus)
+
+	if err := writeSLPPacket(conn, slpHandshakeID, handshake.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to send handshake: %w", err)
+	}
+	if err := writeSLPPacket(conn, slpStatusID, nil); err != nil {
+		return nil, fmt.Errorf("failed to send status request: %w", err)
+	}
+
+	id, payload, err := readSLPPacket(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status response: %w", err)
+	}
+	if id != slpStatusID {
+		return nil, fmt.Errorf("unexpected packet 0x%02x in status response", id)
+	}
+	latency := time.Since(started)
+
+	data, err := readString(bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status response: %w", err)
+	}
+
+	var status slpStatus
+	if err := json.Unmarshal([]byte(data), &status); err != nil {
+		return nil, fmt.Errorf("failed to parse status response: %w", err)
+	}
+
+	result := &PingResult{
+		Version:       status.Version.Name,
+		Protocol:      status.Version.Protocol,
+		MOTD:          parseDescription(status.Description),
+		OnlinePlayers: status.Players.Online,
+		MaxPlayers:    status.Players.Max,
+		Latency:       latency,
+	}
+	for _, player := range status.Players.Sample {
+		result.Players = append(result.Players, player.Name)
+	}
+
+	// Measure latency with a ping. Some proxies close the connection after
+	// the status response, so the status round trip is used instead.
+	if rtt, err := slpPing(conn, reader); err == nil {
+		result.Latency = rtt
+	}
+
+	return result, nil
+}
+
+// slpPing sends a ping packet and returns the round trip time
+func slpPing(conn net.Conn, reader *bufio.Reader) (time.Duration, error) {
+	started := time.Now()
+	token := started.UnixNano()
+
+	payload := make([]byte, 8)
+	binary.BigEndian.PutUint64(payload, uint64(token))
+	if err := writeSLPPacket(conn, slpPingID, payload); err != nil {
+		return 0, err
+	}
+
+	id, pong, err := readSLPPacket(reader)
+	if err != nil {
+		return 0, err
+	}
+	if id != slpPingID || len(pong) != 8 || int64(binary.BigEndian.Uint64(pong)) != token {
+		return 0, fmt.Errorf("invalid pong response")
+	}
+
+	return time.Since(started), nil
+}
+
+// parseDescription flattens a MOTD that is either a plain string or a chat
+// component into plain text
+func parseDescription(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		var component chatComponent
+		if err := json.Unmarshal(raw, &component); err != nil {
+			return ""
+		}
+		text = component.plainText()
+	}
+
+	return strings.TrimSpace(formattingCodePattern.ReplaceAllString(text, ""))
+}
+
+// chatComponent is the subset of a chat component needed for plain text
+type chatComponent struct {
+	Text  string            `json:"text"`
+	Extra []json.RawMessage `json:"extra"`
+}
+
+// plainText concatenates the text of a component and its children
+func (c chatComponent) plainText() string {
+	var builder strings.Builder
+	builder.WriteString(c.Text)
+
+	for _, raw := range c.Extra {
+		var text string
+		if err := json.Unmarshal(raw, &text); err == nil {
+			builder.WriteString(text)
+			continue
+		}
+		var child chatComponent
+		if err := json.Unmarshal(raw, &child); err == nil {
+			builder.WriteString(child.plainText())
+		}
+	}
+
+	return builder.String()
+}
+
+// writeSLPPacket writes a length-prefixed packet
+func writeSLPPacket(w io.Writer, id int32, payload []byte) error {
+	body := new(bytes.Buffer)
+	writeVarInt(body, id)
+	body.Write(payload)
+
+	packet := new(bytes.Buffer)
+	writeVarInt(packet, int32(body.Len()))
+	packet.Write(body.Bytes())
+
+	_, err := w.Write(packet.Bytes())
+	return err
+}
+
+// readSLPPacket reads a length-prefixed packet and returns its ID and payload
+func readSLPPacket(r *bufio.Reader) (int32, []byte, error) {
+	length, err := readVarInt(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if length < 1 || length > slpMaxPacketLength {
+		return 0, nil, fmt.Errorf("invalid packet length %d", length)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return 0, nil, err
+	}
+
+	body := bytes.NewReader(data)
+	id, err := readVarInt(body)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return id, data[len(data)-body.Len():], nil
+}
+
+// writeVarInt writes a VarInt: 7 bits per byte, least significant group
+// first, with the high bit set on all but the last byte
+func writeVarInt(buf *bytes.Buffer, value int32) {
+	v := uint32(value)
+	for {
+		if v&^0x7F == 0 {
+			buf.WriteByte(byte(v))
+			return
+		}
+		buf.WriteByte(byte(v&0x7F | 0x80))
+		v >>= 7
+	}
+}
+
+// readVarInt reads a VarInt of at most 5 bytes
+func readVarInt(r io.ByteReader) (int32, error) {
+	var value uint32
+	for i := 0; i < 5; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		value |= uint32(b&0x7F) << (7 * i)
+		if b&0x80 == 0 {
+			return int32(value), nil
+		}
+	}
+	return 0, errors.New("VarInt is too long")
+}
+
+// writeString writes a VarInt length-prefixed UTF-8 string
+func writeString(buf *bytes.Buffer, s string) {
+	writeVarInt(buf, int32(len(s)))
+	buf.WriteString(s)
+}
+
+// readString reads a VarInt length-prefixed UTF-8 string
+func readString(r *bytes.Reader) (string, error) {
+	length, err := readVarInt(r)
+	if err != nil {
+		return "", err
+	}
+	if length < 0 || int(length) > r.Len() {
+		return "", fmt.Errorf("invalid string length %d", length)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Ping queries the running server with Server List Ping
+func (m *Manager) Ping(timeout time.Duration) (*PingResult, error) {
+	port := 25565
+	if m.config != nil && m.config.ServerConfig.Port != 0 {
+		port = m.config.ServerConfig.Port
+	}
+
+	return Ping("127.0.0.1", port, timeout)
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakeSLPServer answers one Server List Ping with the given status JSON
+func fakeSLPServer(t *testing.T, status string, answerPing bool) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		reader := bufio.NewReader(conn)
+
+		// Handshake, then status request
+		if id, _, err := readSLPPacket(reader); err != nil || id != slpHandshakeID {
+			return
+		}
+		if id, _, err := readSLPPacket(reader); err != nil || id != slpStatusID {
+			return
+		}
+
+		payload := new(bytes.Buffer)
+		writeString(payload, status)
+		if err := writeSLPPacket(conn, slpStatusID, payload.Bytes()); err != nil {
+			return
+		}
+
+		if !answerPing {
+			return
+		}
+		id, ping, err := readSLPPacket(reader)
+		if err != nil || id != slpPingID {
+			return
+		}
+		_ = writeSLPPacket(conn, slpPingID, ping)
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestPing(t *testing.T) {
+	status := `{
+		"version": {"name": "Paper 1.21.4", "protocol": 769},
+		"players": {"max": 20, "online": 2, "sample": [{"name": "dev", "id": "0"}, {"name": "alex", "id": "1"}]},
+		"description": {"text": "§aA ", "extra": [{"text": "Minecraft"}, " Server"]}
+	}`
+	port := fakeSLPServer(t, status, true)
+
+	result, err := Ping("127.0.0.1", port, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+
+	if result.Version != "Paper 1.21.4" || result.Protocol != 769 {
+		t.Errorf("Ping() version = %s (protocol %d)", result.Version, result.Protocol)
+	}
+	if result.MOTD != "A Minecraft Server" {
+		t.Errorf("Ping() MOTD = %q, want %q", result.MOTD, "A Minecraft Server")
+	}
+	if result.OnlinePlayers != 2 || result.MaxPlayers != 20 {
+		t.Errorf("Ping() players = %d/%d, want 2/20", result.OnlinePlayers, result.MaxPlayers)
+	}
+	if len(result.Players) != 2 || result.Players[0] != "dev" {
+		t.Errorf("Ping() sample = %v", result.Players)
+	}
+	if result.Latency <= 0 {
+		t.Errorf("Ping() latency = %v, want > 0", result.Latency)
+	}
+}
+
+func TestPingWithoutPong(t *testing.T) {
+	port := fakeSLPServer(t, `{"version": {"name": "Velocity 3.4.0", "protocol": 769}, "players": {"max": 500, "online": 0}, "description": "Proxy"}`, false)
+
+	result, err := Ping("127.0.0.1", port, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if result.MOTD != "Proxy" || result.Latency <= 0 {
+		t.Errorf("Ping() = %+v", result)
+	}
+}
+
+func TestPingConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	_ = listener.Close()
+
+	if _, err := Ping("127.0.0.1", port, time.Second); err == nil {
+		t.Error("Ping() should fail when nothing is listening")
+	}
+}
+
+func TestVarInt(t *testing.T) {
+	tests := []struct {
+		value int32
+		want  []byte
+	}{
+		{0, []byte{0x00}},
+		{1, []byte{0x01}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x80, 0x01}},
+		{25565, []byte{0xdd, 0xc7, 0x01}},
+		{2147483647, []byte{0xff, 0xff, 0xff, 0xff, 0x07}},
+		{-1, []byte{0xff, 0xff, 0xff, 0xff, 0x0f}},
+	}
+
+	for _, tt := range tests {
+		buf := new(bytes.Buffer)
+		writeVarInt(buf, tt.value)
+		if !bytes.Equal(buf.Bytes(), tt.want) {
+			t.Errorf("writeVarInt(%d) = %x, want %x", tt.value, buf.Bytes(), tt.want)
+		}
+
+		got, err := readVarInt(bytes.NewReader(tt.want))
+		if err != nil || got != tt.value {
+			t.Errorf("readVarInt(%x) = %d, %v, want %d", tt.want, got, err, tt.value)
+		}
+	}
+}
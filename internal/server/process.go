@@ -6,20 +6,54 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path/filepath"
 	"runtime"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
+
+	"github.com/jackh54/mcinit/internal/utils"
 )
 
+// StopOptions configures the stages of a graceful shutdown
+type StopOptions struct {
+	RCON         *RCONClient   // nil disables the RCON stage
+	RCONTimeout  time.Duration // wait after "stop" via RCON
+	StdinTimeout time.Duration // wait after "stop" via the console
+	TermTimeout  time.Duration // wait after SIGTERM before SIGKILL
+}
+
+// supervisorExitTimeout is how long a supervisor waiting to restart the
+// server may take to exit
+const supervisorExitTimeout = 10 * time.Second
+
+// DefaultStopOptions returns the stop options used when no config is available
+func DefaultStopOptions() StopOptions {
+	return StopOptions{
+		RCONTimeout:  30 * time.Second,
+		StdinTimeout: 30 * time.Second,
+		TermTimeout:  30 * time.Second,
+	}
+}
+
 // Process manages a server process
 type Process struct {
 	cmd       *exec.Cmd
 	stdin     io.WriteCloser
+	stdinMu   sync.Mutex
+	inputOnce sync.Once
 	stdout    io.ReadCloser
 	stderr    io.ReadCloser
 	pid       int
+	done      chan struct{}
 	serverDir string
 	stateFile *StateFile
+	stopOpts  StopOptions
+	proxy     bool // the server is a proxy, see isProxyType
+	console   *consoleHub
+	ready     atomic.Bool // the current run has logged that it is ready
 }
 
 // NewProcess creates a new Process instance
@@ -27,13 +61,41 @@ func NewProcess(serverDir string) *Process {
 	return &Process{
 		serverDir: serverDir,
 		stateFile: NewStateFile(serverDir),
+		stopOpts:  DefaultStopOptions(),
 	}
 }
 
-// Start starts the server process
-func (p *Process) Start(javaPath, jarPath string, jvmArgs []string, background bool) error {
+// SetStopOptions sets the options used by Stop
+func (p *Process) SetStopOptions(opts StopOptions) {
+	p.stopOpts = opts
+}
+
+// SetServerType sets the configured server type, which selects the console
+// lines that show the server is ready
+func (p *Process) SetServerType(serverType string) {
+	p.proxy = isProxyType(serverType)
+}
+
+// RunOptions configures how the server console is connected
+type RunOptions struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	Stdin  io.Reader // nil leaves the console without an input source
+
+	// ConsoleSocket is the path of a Unix socket that clients can attach
+	// to, or empty to not serve the console
+	ConsoleSocket string
+}
+
+// Run starts the server process and blocks until it exits. Interrupts are
+// turned into a graceful shutdown, and the exit is recorded in the state file.
+// launchArgs select what to run, such as "-jar server.jar".
+func (p *Process) Run(javaPath string, jvmArgs, launchArgs []string, opts RunOptions) error {
 	// Build command arguments
-	args := append(jvmArgs, "-jar", jarPath, "nogui")
+	args := make([]string, 0, len(jvmArgs)+len(launchArgs)+1)
+	args = append(args, jvmArgs...)
+	args = append(args, launchArgs...)
+	args = append(args, "nogui")
 
 	// Create command
 	p.cmd = exec.Command(javaPath, args...)
@@ -44,7 +106,9 @@ func (p *Process) Start(javaPath, jarPath string, jvmArgs []string, background b
 	if err != nil {
 		return fmt.Errorf("failed to create stdin pipe: %w", err)
 	}
+	p.stdinMu.Lock()
 	p.stdin = stdin
+	p.stdinMu.Unlock()
 
 	// Set up stdout
 	stdout, err := p.cmd.StdoutPipe()
@@ -69,46 +133,174 @@ func (p *Process) Start(javaPath, jarPath string, jvmArgs []string, background b
 	}
 
 	p.pid = p.cmd.Process.Pid
+	p.done = make(chan struct{})
+	p.ready.Store(false)
 
-	// Save state
+	// Save state, keeping how the previous run ended
 	state := &State{
-		PID:       p.pid,
-		StartTime: time.Now(),
-		Status:    "running",
+		PID:           p.pid,
+		SupervisorPID: os.Getpid(),
+		StartTime:     time.Now(),
+		Status:        StatusRunning,
+	}
+	if identity, err := readProcIdentity(p.pid); err == nil {
+		state.ProcStartTime = identity.StartTime
+		state.Cmdline = identity.Cmdline
+	}
+	if identity, err := readProcIdentity(os.Getpid()); err == nil {
+		state.SupervisorStartTime = identity.StartTime
+	}
+	if previous, err := p.stateFile.Load(); err == nil {
+		state.LastExit = previous.LastExit
+		// Automatic restarts keep counting until the server is started by hand
+		if previous.Status == StatusRestarting {
+			state.Restarts = previous.Restarts
+			state.RestartEvents = previous.RestartEvents
+		}
 	}
 	if err := p.stateFile.Save(state); err != nil {
+		_ = p.cmd.Process.Kill()
+		_ = p.cmd.Wait()
 		return fmt.Errorf("failed to save state: %w", err)
 	}
 
-	if background {
-		// In background mode, start goroutines to handle output
-		go p.pipeOutput(p.stdout, os.Stdout)
-		go p.pipeOutput(p.stderr, os.Stderr)
-		
-		// Start goroutine to wait for process and update state
-		go func() {
-			_ = p.cmd.Wait()
-			_ = p.stateFile.Clear()
-		}()
-	} else {
-		// In foreground mode, pipe output synchronously
-		go p.pipeOutput(p.stdout, os.Stdout)
-		go p.pipeOutput(p.stderr, os.Stderr)
-		
-		// Wait for process to complete
-		if err := p.cmd.Wait(); err != nil {
-			_ = p.stateFile.Clear()
-			return fmt.Errorf("server process exited with error: %w", err)
+	// Serve the console to attached clients
+	if opts.ConsoleSocket != "" {
+		hub, err := listenConsole(opts.ConsoleSocket, p.SendInput)
+		if err != nil {
+			fmt.Printf("Console will not be attachable: %v\n", err)
+		} else {
+			p.console = hub
+			defer func() { _ = hub.Close() }()
 		}
-		_ = p.stateFile.Clear()
 	}
 
+	// Pipe console output
+	var output sync.WaitGroup
+	output.Add(2)
+	go func() {
+		defer output.Done()
+		p.pipeOutput(p.stdout, opts.Stdout)
+	}()
+	go func() {
+		defer output.Done()
+		p.pipeOutput(p.stderr, opts.Stderr)
+	}()
+
+	// Forward typed commands to the server console. The reader is shared
+	// by restarts of the server, so it is only consumed once.
+	if opts.Stdin != nil {
+		p.inputOnce.Do(func() {
+			go p.forwardInput(opts.Stdin)
+		})
+	}
+
+	// The server runs in its own process group, so Ctrl+C does not
+	// reach it. Shut it down gracefully instead.
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		select {
+		case <-sigChan:
+			_ = p.stateFile.MarkStopping()
+			fmt.Printf("Stopping server...\n")
+			if err := p.stopOwned(); err != nil {
+				fmt.Printf("Failed to stop server: %v\n", err)
+			}
+		case <-p.done:
+		}
+	}()
+
+	// Wait for process to complete. Output must be drained before Wait
+	// closes the pipes.
+	output.Wait()
+	err = p.cmd.Wait()
+	close(p.done)
+
+	if recordErr := p.recordExit(); recordErr != nil {
+		fmt.Printf("Failed to record server exit: %v\n", recordErr)
+	}
+
+	if err != nil {
+		return fmt.Errorf("server process exited with error: %w", err)
+	}
 	return nil
 }
 
+// recordExit stores how the owned process exited in the state file. An exit
+// that was not requested through Stop is a crash if it was not clean or left
+// a crash report behind.
+func (p *Process) recordExit() error {
+	state, err := p.stateFile.Load()
+	if err != nil || state.PID != p.pid {
+		// The state was cleared or taken over by another server
+		return nil
+	}
+
+	processState := p.cmd.ProcessState
+	exit := &ExitInfo{
+		ExitCode:    processState.ExitCode(),
+		EndTime:     time.Now(),
+		CrashReport: findCrashReport(p.serverDir, state.StartTime),
+	}
+	if status, ok := processState.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		exit.Signal = status.Signal().String()
+	}
+
+	exit.Requested = state.Status == StatusStopping
+	exit.Crashed = !exit.Requested && (exit.ExitCode != 0 || exit.Signal != "" || exit.CrashReport != "")
+
+	state.LastExit = exit
+	if exit.Crashed {
+		state.Status = StatusCrashed
+	} else {
+		state.Status = StatusStopped
+	}
+
+	return p.stateFile.Save(state)
+}
+
+// findCrashReport returns the newest crash report written since the given
+// time, relative to the server directory, or "" if there is none
+func findCrashReport(serverDir string, since time.Time) string {
+	entries, err := os.ReadDir(filepath.Join(serverDir, "crash-reports"))
+	if err != nil {
+		return ""
+	}
+
+	var newest string
+	var newestTime time.Time
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().Before(since) {
+			continue
+		}
+		if info.ModTime().After(newestTime) {
+			newest = entry.Name()
+			newestTime = info.ModTime()
+		}
+	}
+
+	if newest == "" {
+		return ""
+	}
+	return filepath.Join("crash-reports", newest)
+}
+
 // Stop stops the server process gracefully
 func (p *Process) Stop(force bool) error {
-	// Read PID from state
+	// Only signal the PID while it still belongs to the server
+	if !p.IsRunning() {
+		if p.IsRestarting() {
+			return p.cancelRestart()
+		}
+		return fmt.Errorf("server not running or PID file not found")
+	}
+
 	pid, err := p.stateFile.ReadPID()
 	if err != nil {
 		return fmt.Errorf("server not running or PID file not found")
@@ -120,13 +312,16 @@ func (p *Process) Stop(force bool) error {
 		return fmt.Errorf("failed to find process: %w", err)
 	}
 
+	// Let whoever owns the process know the exit is intentional
+	_ = p.stateFile.MarkStopping()
+
 	if force {
 		// Force kill
 		return p.kill(process)
 	}
 
 	// Try graceful shutdown first
-	if err := p.gracefulStop(process, pid); err != nil {
+	if err := p.gracefulStop(process); err != nil {
 		fmt.Printf("Graceful shutdown failed, forcing...\n")
 		return p.kill(process)
 	}
@@ -134,40 +329,216 @@ func (p *Process) Stop(force bool) error {
 	return nil
 }
 
-// gracefulStop attempts a graceful shutdown via STDIN "stop" command
-func (p *Process) gracefulStop(process *os.Process, pid int) error {
-	// Try to send "stop" command via stdin if we have access
-	// This is a simplified version - in production, you'd need to handle this more carefully
-	
-	// Send SIGTERM (Unix) or appropriate signal (Windows)
-	if err := process.Signal(syscall.SIGTERM); err != nil {
-		return fmt.Errorf("failed to send SIGTERM: %w", err)
+// cancelRestart stops a supervisor that is waiting to restart the server
+func (p *Process) cancelRestart() error {
+	state, err := p.stateFile.Load()
+	if err != nil {
+		return err
+	}
+
+	if !state.SupervisorAlive() {
+		state.Status = StatusStopped
+		return p.stateFile.Save(state)
+	}
+
+	supervisor, err := os.FindProcess(state.SupervisorPID)
+	if err != nil {
+		return fmt.Errorf("failed to find supervisor: %w", err)
+	}
+
+	// Signals other than kill are not supported everywhere
+	if err := supervisor.Signal(syscall.SIGTERM); err != nil {
+		if err := supervisor.Kill(); err != nil {
+			return fmt.Errorf("failed to stop supervisor: %w", err)
+		}
+	}
+
+	if !p.waitForExit(supervisor, supervisorExitTimeout) {
+		return fmt.Errorf("supervisor did not exit")
 	}
 
-	// Wait for process to exit (with timeout)
-	timeout := time.After(30 * time.Second)
+	// The supervisor records the stop itself unless it was killed
+	if state, err := p.stateFile.Load(); err == nil && state.Status == StatusRestarting {
+		state.Status = StatusStopped
+		return p.stateFile.Save(state)
+	}
+	return nil
+}
+
+// stopOwned stops a server started by this Process
+func (p *Process) stopOwned() error {
+	if err := p.gracefulStop(p.cmd.Process); err != nil {
+		fmt.Printf("Graceful shutdown failed, forcing...\n")
+		return p.kill(p.cmd.Process)
+	}
+	return nil
+}
+
+// stopStage is a single step of a graceful shutdown
+type stopStage struct {
+	name    string
+	timeout time.Duration
+	send    func() error
+}
+
+// gracefulStop walks through the shutdown stages in order: "stop" via RCON,
+// "stop" via the console when this process owns it, then SIGTERM. Each stage
+// gets its own timeout before moving on to the next one; stages with a zero
+// timeout are skipped.
+func (p *Process) gracefulStop(process *os.Process) error {
+	var stages []stopStage
+
+	if p.stopOpts.RCON != nil {
+		stages = append(stages, stopStage{
+			name:    "RCON",
+			timeout: p.stopOpts.RCONTimeout,
+			send: func() error {
+				defer func() { _ = p.stopOpts.RCON.Close() }()
+				return p.stopOpts.RCON.Stop()
+			},
+		})
+	}
+
+	if p.ownsConsole() {
+		stages = append(stages, stopStage{
+			name:    "console",
+			timeout: p.stopOpts.StdinTimeout,
+			send: func() error {
+				return p.SendInput("stop")
+			},
+		})
+	} else if utils.PathExists(ConsoleSocketPath(p.serverDir)) {
+		// A supervisor owns the console, send "stop" through it
+		stages = append(stages, stopStage{
+			name:    "console",
+			timeout: p.stopOpts.StdinTimeout,
+			send: func() error {
+				client, err := DialConsole(p.serverDir, ConsoleModeExec)
+				if err != nil {
+					return err
+				}
+				defer func() { _ = client.Close() }()
+				return client.Send("stop")
+			},
+		})
+	}
+
+	stages = append(stages, stopStage{
+		name:    "SIGTERM",
+		timeout: p.stopOpts.TermTimeout,
+		send: func() error {
+			return process.Signal(syscall.SIGTERM)
+		},
+	})
+
+	for _, stage := range stages {
+		if stage.timeout <= 0 {
+			continue
+		}
+		if err := stage.send(); err != nil {
+			fmt.Printf("Could not stop server via %s: %v\n", stage.name, err)
+			continue
+		}
+
+		if p.waitForExit(process, stage.timeout) {
+			p.finishStop()
+			return nil
+		}
+
+		fmt.Printf("Server did not stop within %s after %s stop\n", stage.timeout, stage.name)
+	}
+
+	return fmt.Errorf("graceful shutdown timed out")
+}
+
+// finishStop cleans up after the server exited. When the server is owned by
+// this process or a supervisor, the owner records the exit itself.
+func (p *Process) finishStop() {
+	if p.ownsConsole() {
+		return
+	}
+
+	state, err := p.stateFile.Load()
+	if err == nil && !state.IsActive() {
+		// The supervisor already recorded the exit and quit
+		return
+	}
+	if err == nil && state.SupervisorAlive() {
+		// Give the supervisor a moment to record the exit
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if state, err := p.stateFile.Load(); err != nil || !state.IsActive() {
+				return
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	_ = p.stateFile.MarkStopped()
+}
+
+// waitForExit waits up to timeout for the process to exit
+func (p *Process) waitForExit(process *os.Process, timeout time.Duration) bool {
+	// A child we own is reaped by cmd.Wait, which closes done
+	if p.ownsConsole() {
+		select {
+		case <-p.done:
+			return true
+		case <-time.After(timeout):
+			return false
+		}
+	}
+
+	deadline := time.After(timeout)
 	ticker := time.NewTicker(500 * time.Millisecond)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-timeout:
-			return fmt.Errorf("graceful shutdown timed out")
+		case <-deadline:
+			return false
 		case <-ticker.C:
 			// Check if process still exists
 			if err := process.Signal(syscall.Signal(0)); err != nil {
 				// Process is gone
-				_ = p.stateFile.Clear()
-				return nil
+				return true
 			}
 		}
 	}
 }
 
+// ownsConsole reports whether this Process started the server and holds its stdin
+func (p *Process) ownsConsole() bool {
+	return p.stdin != nil && p.done != nil
+}
+
+// SendInput writes a line to the server console
+func (p *Process) SendInput(line string) error {
+	if !p.ownsConsole() {
+		return fmt.Errorf("server console is not attached")
+	}
+
+	p.stdinMu.Lock()
+	defer p.stdinMu.Unlock()
+
+	_, err := io.WriteString(p.stdin, line+"\n")
+	return err
+}
+
+// forwardInput forwards lines read from reader to the server console
+func (p *Process) forwardInput(reader io.Reader) {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		if err := p.SendInput(scanner.Text()); err != nil {
+			return
+		}
+	}
+}
+
 // kill forcefully kills the process
 func (p *Process) kill(process *os.Process) error {
 	var err error
-	
+
 	if runtime.GOOS == "windows" {
 		err = process.Kill()
 	} else {
@@ -180,8 +551,8 @@ func (p *Process) kill(process *os.Process) error {
 
 	// Wait a bit for process to die
 	time.Sleep(time.Second)
-	_ = p.stateFile.Clear()
-	
+	p.finishStop()
+
 	return nil
 }
 
@@ -192,15 +563,55 @@ func (p *Process) IsRunning() bool {
 		return false
 	}
 
-	// Try to find the process
+	state, err := p.stateFile.Load()
+	if err != nil || state.PID != pid {
+		// A PID file without matching state cannot be verified
+		if processAlive(pid) {
+			return true
+		}
+		p.cleanStaleState(state)
+		return false
+	}
+
+	if state.ServerAlive() {
+		return true
+	}
+	p.cleanStaleState(state)
+	return false
+}
+
+// cleanStaleState removes state files left behind by a server that died
+// without its owner recording the exit, e.g. after a reboot. While the
+// owning mcinit process lives, it records the exit itself.
+func (p *Process) cleanStaleState(state *State) {
+	if state != nil && state.SupervisorAlive() {
+		return
+	}
+
+	if err := p.stateFile.Clear(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to remove stale server state: %v\n", err)
+		return
+	}
+	_ = os.Remove(ConsoleSocketPath(p.serverDir))
+	fmt.Fprintf(os.Stderr, "Removed stale server state (the recorded server process is no longer running)\n")
+}
+
+// processAlive checks whether a process with the given PID exists
+func processAlive(pid int) bool {
 	process, err := os.FindProcess(pid)
 	if err != nil {
 		return false
 	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
 
-	// Check if process is alive by sending signal 0 (doesn't actually send a signal)
-	err = process.Signal(syscall.Signal(0))
-	return err == nil
+// IsRestarting checks if a supervisor is waiting to restart the server
+func (p *Process) IsRestarting() bool {
+	state, err := p.stateFile.Load()
+	if err != nil || state.Status != StatusRestarting {
+		return false
+	}
+	return state.SupervisorAlive()
 }
 
 // GetPID returns the process PID
@@ -217,6 +628,25 @@ func (p *Process) GetState() (*State, error) {
 func (p *Process) pipeOutput(reader io.Reader, writer io.Writer) {
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
-		_, _ = fmt.Fprintln(writer, scanner.Text())
+		line := scanner.Text()
+		_, _ = fmt.Fprintln(writer, line)
+		if p.console != nil {
+			p.console.broadcast(line)
+		}
+		if !p.ready.Load() {
+			p.checkReady(line)
+		}
+	}
+}
+
+// checkReady records the first console line that shows the server is ready
+func (p *Process) checkReady(line string) {
+	reported, ok := detectReady(line, p.proxy)
+	if !ok || !p.ready.CompareAndSwap(false, true) {
+		return
+	}
+
+	if err := p.markReady(reported); err != nil {
+		fmt.Printf("Failed to record server readiness: %v\n", err)
 	}
 }
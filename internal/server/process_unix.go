@@ -11,3 +11,10 @@ func (p *Process) setupProcessGroup() {
 	}
 }
 
+// detachedProcAttr returns attributes that detach a process from the
+// terminal session so it outlives the parent
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		Setsid: true,
+	}
+}
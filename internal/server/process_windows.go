@@ -12,3 +12,11 @@ func (p *Process) setupProcessGroup() {
 	}
 }
 
+// detachedProcAttr returns attributes that detach a process from the
+// console so it outlives the parent
+func detachedProcAttr() *syscall.SysProcAttr {
+	const detachedProcess = 0x00000008
+	return &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess,
+	}
+}
@@ -1,30 +1,307 @@
 package server
 
-// RCON client for graceful server shutdown
-// This is a placeholder for future implementation
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
 
+// RCON packet types (Source RCON protocol, as used by Minecraft)
+const (
+	rconTypeResponse  int32 = 0
+	rconTypeCommand   int32 = 2
+	rconTypeAuthReply int32 = 2
+	rconTypeAuth      int32 = 3
+)
+
+const (
+	rconMaxPayload       = 4096
+	rconMaxPacketLength  = 4 + 4 + rconMaxPayload + 2
+	rconMaxCommandLength = 1446 // Minecraft reads requests into a 1460 byte buffer
+	rconDefaultTimeout   = 10 * time.Second
+)
+
+// ErrRCONAuth is returned when the server rejects the RCON password
+var ErrRCONAuth = errors.New("rcon authentication failed")
+
+// RCONClient is a client for the Source RCON protocol
 type RCONClient struct {
 	host     string
 	port     int
 	password string
+	timeout  time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	nextID int32
 }
 
+// NewRCONClient creates a new RCONClient instance
 func NewRCONClient(host string, port int, password string) *RCONClient {
 	return &RCONClient{
 		host:     host,
 		port:     port,
 		password: password,
+		timeout:  rconDefaultTimeout,
+	}
+}
+
+// SetTimeout sets the timeout used for dialing and for each request
+func (r *RCONClient) SetTimeout(timeout time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timeout = timeout
+}
+
+// Address returns the host:port the client connects to
+func (r *RCONClient) Address() string {
+	return net.JoinHostPort(r.host, strconv.Itoa(r.port))
+}
+
+// Connect opens the connection and authenticates
+func (r *RCONClient) Connect() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conn != nil {
+		return nil
+	}
+	return r.connect()
+}
+
+// Close closes the connection
+func (r *RCONClient) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conn == nil {
+		return nil
 	}
+	err := r.conn.Close()
+	r.conn = nil
+	return err
 }
 
+// SendCommand executes a command on the server and returns its output.
+// The client connects and authenticates on first use.
 func (r *RCONClient) SendCommand(command string) (string, error) {
-	// TODO: Implement RCON protocol
-	// For now, this is just a placeholder
-	return "", nil
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(command) > rconMaxCommandLength {
+		return "", fmt.Errorf("command too long (%d bytes, max %d)", len(command), rconMaxCommandLength)
+	}
+
+	if r.conn == nil {
+		if err := r.connect(); err != nil {
+			return "", err
+		}
+	}
+
+	response, err := r.execute(command)
+	if err != nil {
+		// The connection is in an unknown state, drop it
+		_ = r.conn.Close()
+		r.conn = nil
+		return "", err
+	}
+
+	return response, nil
 }
 
+// Stop sends the "stop" command. The server usually closes the connection
+// while shutting down, so a dropped connection counts as success.
 func (r *RCONClient) Stop() error {
+	// Connect up front so that connection failures are not mistaken for
+	// the server hanging up on us
+	if err := r.Connect(); err != nil {
+		return err
+	}
+
 	_, err := r.SendCommand("stop")
+	if err != nil && isConnectionClosed(err) {
+		return nil
+	}
+	return err
+}
+
+// connect dials the server and authenticates. Caller must hold r.mu.
+func (r *RCONClient) connect() error {
+	conn, err := net.DialTimeout("tcp", r.Address(), r.timeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to RCON at %s: %w", r.Address(), err)
+	}
+	r.conn = conn
+
+	if err := r.authenticate(); err != nil {
+		_ = conn.Close()
+		r.conn = nil
+		return err
+	}
+
+	return nil
+}
+
+// authenticate sends the auth packet and waits for the auth reply
+func (r *RCONClient) authenticate() error {
+	if err := r.conn.SetDeadline(time.Now().Add(r.timeout)); err != nil {
+		return err
+	}
+
+	id := r.newID()
+	if err := writeRCONPacket(r.conn, id, rconTypeAuth, r.password); err != nil {
+		return fmt.Errorf("failed to send RCON auth: %w", err)
+	}
+
+	for {
+		pkt, err := readRCONPacket(r.conn)
+		if err != nil {
+			return fmt.Errorf("failed to read RCON auth reply: %w", err)
+		}
+
+		// Some servers send an empty response value before the auth reply
+		if pkt.Type != rconTypeAuthReply {
+			continue
+		}
+		if pkt.ID == -1 {
+			return ErrRCONAuth
+		}
+		if pkt.ID != id {
+			return fmt.Errorf("unexpected RCON auth reply id %d (want %d)", pkt.ID, id)
+		}
+		return nil
+	}
+}
+
+// execute sends a command and reads its response. Minecraft reads each
+// request with a single read and drops the connection if that read holds
+// more than one packet, so nothing else may be sent before the reply. Only
+// a reply filling a whole packet may have been split: then a sentinel
+// packet is sent and fragments are collected until its reply arrives.
+func (r *RCONClient) execute(command string) (string, error) {
+	if err := r.conn.SetDeadline(time.Now().Add(r.timeout)); err != nil {
+		return "", err
+	}
+
+	id := r.newID()
+	if err := writeRCONPacket(r.conn, id, rconTypeCommand, command); err != nil {
+		return "", fmt.Errorf("failed to send RCON command: %w", err)
+	}
+
+	first, err := r.readResponse(id)
+	if err != nil {
+		return "", err
+	}
+	if len(first) < rconMaxPayload {
+		return first, nil
+	}
+
+	sentinel := r.newID()
+	if err := writeRCONPacket(r.conn, sentinel, rconTypeResponse, ""); err != nil {
+		return "", fmt.Errorf("failed to send RCON command: %w", err)
+	}
+
+	response := bytes.NewBufferString(first)
+	for {
+		pkt, err := readRCONPacket(r.conn)
+		if err != nil {
+			return "", fmt.Errorf("failed to read RCON response: %w", err)
+		}
+
+		switch pkt.ID {
+		case id:
+			response.WriteString(pkt.Body)
+		case sentinel:
+			return response.String(), nil
+		case -1:
+			return "", ErrRCONAuth
+		}
+	}
+}
+
+// readResponse reads packets until the first response to request id
+func (r *RCONClient) readResponse(id int32) (string, error) {
+	for {
+		pkt, err := readRCONPacket(r.conn)
+		if err != nil {
+			return "", fmt.Errorf("failed to read RCON response: %w", err)
+		}
+
+		switch pkt.ID {
+		case id:
+			return pkt.Body, nil
+		case -1:
+			return "", ErrRCONAuth
+		}
+	}
+}
+
+// newID returns the next request ID. IDs are kept positive since -1 is
+// reserved for authentication failures.
+func (r *RCONClient) newID() int32 {
+	r.nextID++
+	if r.nextID <= 0 {
+		r.nextID = 1
+	}
+	return r.nextID
+}
+
+// rconPacket is a single RCON packet
+type rconPacket struct {
+	ID   int32
+	Type int32
+	Body string
+}
+
+// writeRCONPacket writes a length-prefixed little-endian RCON packet
+func writeRCONPacket(w io.Writer, id, typ int32, body string) error {
+	length := int32(4 + 4 + len(body) + 2)
+
+	buf := bytes.NewBuffer(make([]byte, 0, length+4))
+	_ = binary.Write(buf, binary.LittleEndian, length)
+	_ = binary.Write(buf, binary.LittleEndian, id)
+	_ = binary.Write(buf, binary.LittleEndian, typ)
+	buf.WriteString(body)
+	buf.Write([]byte{0, 0})
+
+	_, err := w.Write(buf.Bytes())
 	return err
 }
 
+// readRCONPacket reads a single RCON packet
+func readRCONPacket(r io.Reader) (*rconPacket, error) {
+	var length int32
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		return nil, err
+	}
+
+	if length < 10 || length > rconMaxPacketLength {
+		return nil, fmt.Errorf("invalid RCON packet length: %d", length)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	return &rconPacket{
+		ID:   int32(binary.LittleEndian.Uint32(data[0:4])),
+		Type: int32(binary.LittleEndian.Uint32(data[4:8])),
+		Body: string(bytes.TrimRight(data[8:], "\x00")),
+	}, nil
+}
+
+// isConnectionClosed reports whether err means the peer closed the connection
+func isConnectionClosed(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && !opErr.Timeout()
+}
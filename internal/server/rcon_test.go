@@ -0,0 +1,286 @@
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRCONServer is a minimal in-process RCON server that behaves like
+// Minecraft's implementation
+type fakeRCONServer struct {
+	listener net.Listener
+	password string
+	handler  func(command string) string
+
+	// readDelay delays each read so that packets sent back to back arrive
+	// in the same read
+	readDelay time.Duration
+
+	mu       sync.Mutex
+	commands []string
+}
+
+func newFakeRCONServer(t *testing.T, password string, handler func(string) string) *fakeRCONServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	s := &fakeRCONServer{
+		listener: listener,
+		password: password,
+		handler:  handler,
+	}
+	go s.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+
+	return s
+}
+
+func (s *fakeRCONServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeRCONServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+func (s *fakeRCONServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeRCONServer) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	authed := false
+	for {
+		pkt, err := s.readPacket(conn)
+		if err != nil {
+			return
+		}
+
+		switch {
+		case pkt.Type == rconTypeAuth:
+			if pkt.Body != s.password {
+				_ = writeRCONPacket(conn, -1, rconTypeAuthReply, "")
+				return
+			}
+			authed = true
+			_ = writeRCONPacket(conn, pkt.ID, rconTypeAuthReply, "")
+
+		case !authed:
+			_ = writeRCONPacket(conn, -1, rconTypeAuthReply, "")
+			return
+
+		case pkt.Type == rconTypeCommand:
+			s.mu.Lock()
+			s.commands = append(s.commands, pkt.Body)
+			s.mu.Unlock()
+
+			if pkt.Body == "stop" {
+				// Minecraft drops RCON connections while shutting down
+				return
+			}
+
+			// Split long responses across packets like Minecraft does
+			response := s.handler(pkt.Body)
+			for len(response) > rconMaxPayload {
+				_ = writeRCONPacket(conn, pkt.ID, rconTypeResponse, response[:rconMaxPayload])
+				response = response[rconMaxPayload:]
+			}
+			_ = writeRCONPacket(conn, pkt.ID, rconTypeResponse, response)
+
+		default:
+			_ = writeRCONPacket(conn, pkt.ID, rconTypeResponse, "Unknown request 0")
+		}
+	}
+}
+
+// readPacket reads a request like Minecraft's RconClient: with a single read
+// into a 1460 byte buffer, failing unless it holds exactly one packet
+func (s *fakeRCONServer) readPacket(conn net.Conn) (*rconPacket, error) {
+	time.Sleep(s.readDelay)
+
+	buf := make([]byte, 1460)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	if n < 4 || int(binary.LittleEndian.Uint32(buf[:4]))+4 != n {
+		return nil, fmt.Errorf("read %d bytes, not a single packet", n)
+	}
+	return readRCONPacket(bytes.NewReader(buf[:n]))
+}
+
+func TestRCONSendCommand(t *testing.T) {
+	srv := newFakeRCONServer(t, "secret", func(cmd string) string {
+		return "echo: " + cmd
+	})
+
+	client := NewRCONClient("127.0.0.1", srv.port(), "secret")
+	defer func() { _ = client.Close() }()
+
+	for _, cmd := range []string{"list", "say hello", "time set day"} {
+		got, err := client.SendCommand(cmd)
+		if err != nil {
+			t.Fatalf("SendCommand(%q) error = %v", cmd, err)
+		}
+		if want := "echo: " + cmd; got != want {
+			t.Errorf("SendCommand(%q) = %q, want %q", cmd, got, want)
+		}
+	}
+
+	if got := srv.received(); len(got) != 3 {
+		t.Errorf("Server received %d commands, want 3", len(got))
+	}
+}
+
+func TestRCONMultiPacketResponse(t *testing.T) {
+	long := strings.Repeat("abcdefghij", 1500) // 15000 bytes, 4 packets
+
+	srv := newFakeRCONServer(t, "secret", func(cmd string) string {
+		return long
+	})
+
+	client := NewRCONClient("127.0.0.1", srv.port(), "secret")
+	defer func() { _ = client.Close() }()
+
+	got, err := client.SendCommand("help")
+	if err != nil {
+		t.Fatalf("SendCommand() error = %v", err)
+	}
+	if got != long {
+		t.Errorf("SendCommand() returned %d bytes, want %d", len(got), len(long))
+	}
+}
+
+func TestRCONSinglePacketPerRead(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+	}{
+		{"short response", "There are 0 of a max of 20 players online"},
+		{"split response", strings.Repeat("abcdefghij", 500)},
+		{"response filling one packet", strings.Repeat("a", rconMaxPayload)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFakeRCONServer(t, "secret", func(cmd string) string { return tt.response })
+			srv.readDelay = 50 * time.Millisecond
+
+			client := NewRCONClient("127.0.0.1", srv.port(), "secret")
+			defer func() { _ = client.Close() }()
+
+			// A dropped connection fails the second command
+			for i := 0; i < 2; i++ {
+				got, err := client.SendCommand("list")
+				if err != nil {
+					t.Fatalf("SendCommand() error = %v", err)
+				}
+				if got != tt.response {
+					t.Errorf("SendCommand() returned %d bytes, want %d", len(got), len(tt.response))
+				}
+			}
+		})
+	}
+}
+
+func TestRCONAuthFailure(t *testing.T) {
+	srv := newFakeRCONServer(t, "secret", func(cmd string) string { return "" })
+
+	client := NewRCONClient("127.0.0.1", srv.port(), "wrong")
+	defer func() { _ = client.Close() }()
+
+	_, err := client.SendCommand("list")
+	if !errors.Is(err, ErrRCONAuth) {
+		t.Errorf("SendCommand() error = %v, want ErrRCONAuth", err)
+	}
+}
+
+func TestRCONStop(t *testing.T) {
+	srv := newFakeRCONServer(t, "secret", func(cmd string) string { return "" })
+
+	client := NewRCONClient("127.0.0.1", srv.port(), "secret")
+	defer func() { _ = client.Close() }()
+
+	if err := client.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	got := srv.received()
+	if len(got) != 1 || got[0] != "stop" {
+		t.Errorf("Server received %v, want [stop]", got)
+	}
+}
+
+func TestRCONConnectionRefused(t *testing.T) {
+	// Grab a free port and close it again so nothing is listening
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	_ = listener.Close()
+
+	client := NewRCONClient("127.0.0.1", port, "secret")
+	client.SetTimeout(time.Second)
+
+	if err := client.Stop(); err == nil {
+		t.Error("Stop() should fail when no server is listening")
+	}
+}
+
+func TestRCONTimeout(t *testing.T) {
+	// A listener that accepts but never answers
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		time.Sleep(2 * time.Second)
+	}()
+
+	client := NewRCONClient("127.0.0.1", listener.Addr().(*net.TCPAddr).Port, "secret")
+	client.SetTimeout(200 * time.Millisecond)
+
+	start := time.Now()
+	if _, err := client.SendCommand("list"); err == nil {
+		t.Error("SendCommand() should time out")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("SendCommand() took %v, expected to time out after ~200ms", elapsed)
+	}
+}
+
+func TestRCONCommandTooLong(t *testing.T) {
+	client := NewRCONClient("127.0.0.1", 1, "secret")
+
+	if _, err := client.SendCommand(strings.Repeat("a", rconMaxCommandLength+1)); err == nil {
+		t.Error("SendCommand() should reject oversized commands")
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var (
+	// Vanilla and its forks log `Done (12.345s)! For help, type "help"`,
+	// Velocity logs `Done (1.23s)!`
+	doneLinePattern = regexp.MustCompile(`Done \((\d+(?:\.\d+)?)s\)!`)
+
+	// BungeeCord and Waterfall log `Listening on /0.0.0.0:25577`. Plugins and
+	// mods of other servers log similar lines, so it only applies to proxies.
+	listeningLinePattern = regexp.MustCompile(`Listening on /?\S+:\d+`)
+)
+
+// readinessPollInterval is how often WaitReady checks the state file
+const readinessPollInterval = 250 * time.Millisecond
+
+// isProxyType reports whether a server type is a proxy, whose readiness is
+// also announced by the address it listens on
+func isProxyType(serverType string) bool {
+	switch serverType {
+	case "bungee", "waterfall", "velocity":
+		return true
+	}
+	return false
+}
+
+// detectReady checks whether a console line announces that the server is
+// accepting players. The returned duration is the startup time reported by
+// the server, or zero if the line does not include one.
+func detectReady(line string, proxy bool) (time.Duration, bool) {
+	if match := doneLinePattern.FindStringSubmatch(line); match != nil {
+		seconds, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return 0, true
+		}
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+
+	if proxy && listeningLinePattern.MatchString(line) {
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// markReady records in the state file that the current run is ready
+func (p *Process) markReady(reported time.Duration) error {
+	state, err := p.stateFile.Load()
+	if err != nil {
+		return err
+	}
+
+	// The state may already describe a newer run or a stop
+	if state.PID != p.pid || !state.IsActive() {
+		return nil
+	}
+
+	now := time.Now()
+	state.Ready = true
+	state.ReadyTime = now
+	state.StartupDuration = reported
+	if reported == 0 {
+		state.StartupDuration = now.Sub(state.StartTime)
+	}
+
+	return p.stateFile.Save(state)
+}
+
+// WaitReady blocks until the server reports that it is accepting players.
+// A server that exits or is still starting after timeout is an error that
+// includes the tail of the console log.
+func (m *Manager) WaitReady(timeout time.Duration) (*State, error) {
+	logPath := ConsoleLogPath(m.serverDir)
+	deadline := time.Now().Add(timeout)
+
+	for {
+		state, err := m.process.GetState()
+		switch {
+		case err == nil && state.Ready && m.process.IsRunning():
+			return state, nil
+		case m.process.IsRunning() || m.process.IsRestarting():
+			// Still starting, or about to be started again
+		case err == nil && state.LastExit != nil:
+			return nil, fmt.Errorf("server exited before becoming ready (%s)%s",
+				state.LastExit.Summary(), logTail(logPath, 20))
+		default:
+			return nil, fmt.Errorf("server is not running%s", logTail(logPath, 20))
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out after %s waiting for the server to become ready%s",
+				timeout, logTail(logPath, 20))
+		}
+		time.Sleep(readinessPollInterval)
+	}
+}
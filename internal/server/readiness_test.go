@@ -0,0 +1,122 @@
+package server
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackh54/mcinit/internal/config"
+)
+
+func TestDetectReady(t *testing.T) {
+	tests := []struct {
+		name       string
+		serverType string
+		line       string
+		wantReady  bool
+		wantTime   time.Duration
+	}{
+		{
+			"vanilla", "vanilla",
+			`[12:00:00] [Server thread/INFO]: Done (12.345s)! For help, type "help"`,
+			true, 12345 * time.Millisecond,
+		},
+		{
+			"velocity", "velocity",
+			`[12:00:00 INFO]: Done (1.50s)!`,
+			true, 1500 * time.Millisecond,
+		},
+		{
+			"waterfall", "waterfall",
+			`12:00:00 [INFO] Listening on /0.0.0.0:25577`,
+			true, 0,
+		},
+		{
+			"paper plugin listening", "paper",
+			`[12:00:00 INFO]: [dynmap] Listening on 0.0.0.0:8123`,
+			false, 0,
+		},
+		{
+			"player chat", "paper",
+			`[12:00:00] [Server thread/INFO]: <dev> Done!`,
+			false, 0,
+		},
+		{
+			"preparing", "vanilla",
+			`[12:00:00] [Server thread/INFO]: Preparing spawn area: 83%`,
+			false, 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := detectReady(tt.line, isProxyType(tt.serverType))
+			if ok != tt.wantReady || got != tt.wantTime {
+				t.Errorf("detectReady() = (%v, %v), want (%v, %v)", got, ok, tt.wantTime, tt.wantReady)
+			}
+		})
+	}
+}
+
+func TestProcessCheckReady(t *testing.T) {
+	mgr := newTestManager(t, func(cfg *config.Config) {})
+	process := mgr.process
+	process.pid = os.Getpid()
+
+	process.checkReady(`[Server thread/INFO]: Done (3.2s)! For help, type "help"`)
+	// Later matches are ignored
+	process.checkReady(`[Server thread/INFO]: Done (9.9s)! For help, type "help"`)
+
+	state, err := mgr.WaitReady(time.Second)
+	if err != nil {
+		t.Fatalf("WaitReady() error = %v", err)
+	}
+	if state.StartupDuration != 3200*time.Millisecond {
+		t.Errorf("StartupDuration = %v, want 3.2s", state.StartupDuration)
+	}
+	if got := state.FormatStartup(); got != "3.2s" {
+		t.Errorf("FormatStartup() = %q, want %q", got, "3.2s")
+	}
+}
+
+func TestProcessCheckReadyIgnoresPluginListening(t *testing.T) {
+	mgr := newTestManager(t, func(cfg *config.Config) { cfg.Server.Type = "paper" })
+	process := mgr.process
+	process.pid = os.Getpid()
+
+	process.checkReady(`[12:00:00 INFO]: [dynmap] Listening on 0.0.0.0:8123`)
+
+	state, err := process.GetState()
+	if err != nil {
+		t.Fatalf("GetState() error = %v", err)
+	}
+	if state.Ready {
+		t.Error("a plugin listening on a port marked the server ready")
+	}
+}
+
+func TestWaitReadyTimeout(t *testing.T) {
+	mgr := newTestManager(t, func(cfg *config.Config) {})
+
+	_, err := mgr.WaitReady(300 * time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("WaitReady() error = %v, want timeout", err)
+	}
+}
+
+func TestWaitReadyExited(t *testing.T) {
+	mgr := newTestManager(t, func(cfg *config.Config) {})
+
+	state, _ := mgr.GetStatus()
+	state.Status = StatusCrashed
+	state.LastExit = &ExitInfo{ExitCode: 1, Crashed: true, EndTime: time.Now()}
+	if err := mgr.process.stateFile.Save(state); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	_, err := mgr.WaitReady(5 * time.Second)
+	if err == nil || !strings.Contains(err.Error(), "exit 1") {
+		t.Errorf("WaitReady() error = %v, want exit before ready", err)
+	}
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"time"
+
+	"github.com/jackh54/mcinit/internal/config"
+)
+
+// maxRestartEvents caps the restart history kept in the state file. It is
+// enough to count MaxRestarts, which the config validation limits.
+const maxRestartEvents = config.MaxRestartsLimit
+
+// RestartEvent records an automatic restart
+type RestartEvent struct {
+	Time     time.Time `json:"time"`
+	ExitCode int       `json:"exitCode"`
+	Crashed  bool      `json:"crashed"`
+	Delay    string    `json:"delay"`
+}
+
+// RestartPolicy decides whether and when a server that exited is restarted
+type RestartPolicy struct {
+	Policy      string
+	MaxRestarts int
+	Window      time.Duration
+	Backoff     time.Duration
+	MaxBackoff  time.Duration // 0 means unbounded
+}
+
+// NewRestartPolicy creates a RestartPolicy from the config
+func NewRestartPolicy(cfg config.RestartConfig) RestartPolicy {
+	return RestartPolicy{
+		Policy:      cfg.Policy,
+		MaxRestarts: cfg.MaxRestarts,
+		Window:      time.Duration(cfg.Window) * time.Second,
+		Backoff:     time.Duration(cfg.Backoff) * time.Second,
+		MaxBackoff:  time.Duration(cfg.MaxBackoff) * time.Second,
+	}
+}
+
+// Next returns the delay before restarting the server described by state,
+// or false if it should stay down
+func (p RestartPolicy) Next(state *State, now time.Time) (time.Duration, bool) {
+	exit := state.LastExit
+	if exit == nil || exit.Requested {
+		return 0, false
+	}
+
+	switch p.Policy {
+	case config.RestartAlways:
+	case config.RestartOnFailure:
+		if !exit.Crashed {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	recent := p.recentRestarts(state, now)
+	if p.MaxRestarts > 0 && recent >= p.MaxRestarts {
+		return 0, false
+	}
+
+	// Exponential backoff over the restarts within the window
+	delay := p.Backoff
+	for i := 0; i < recent && (p.MaxBackoff == 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+
+	return delay, true
+}
+
+// recentRestarts counts the restarts within the window
+func (p RestartPolicy) recentRestarts(state *State, now time.Time) int {
+	count := 0
+	for _, event := range state.RestartEvents {
+		if p.Window == 0 || now.Sub(event.Time) <= p.Window {
+			count++
+		}
+	}
+	return count
+}
+
+// recordRestart adds a restart event to the state
+func recordRestart(state *State, now time.Time, delay time.Duration) {
+	event := RestartEvent{
+		Time:  now,
+		Delay: delay.String(),
+	}
+	if state.LastExit != nil {
+		event.ExitCode = state.LastExit.ExitCode
+		event.Crashed = state.LastExit.Crashed
+	}
+
+	state.Restarts++
+	state.RestartEvents = append(state.RestartEvents, event)
+	if len(state.RestartEvents) > maxRestartEvents {
+		state.RestartEvents = state.RestartEvents[len(state.RestartEvents)-maxRestartEvents:]
+	}
+	state.Status = StatusRestarting
+	state.NextRestart = now.Add(delay)
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackh54/mcinit/internal/config"
+)
+
+func TestRestartPolicyNext(t *testing.T) {
+	now := time.Now()
+	crashed := &ExitInfo{ExitCode: 1, Crashed: true, EndTime: now}
+	clean := &ExitInfo{ExitCode: 0, EndTime: now}
+	requested := &ExitInfo{ExitCode: 0, Requested: true, EndTime: now}
+
+	tests := []struct {
+		name      string
+		policy    string
+		exit      *ExitInfo
+		wantDelay time.Duration
+		wantOK    bool
+	}{
+		{"never after crash", config.RestartNever, crashed, 0, false},
+		{"on-failure after crash", config.RestartOnFailure, crashed, 5 * time.Second, true},
+		{"on-failure after clean exit", config.RestartOnFailure, clean, 0, false},
+		{"always after clean exit", config.RestartAlways, clean, 5 * time.Second, true},
+		{"always after requested stop", config.RestartAlways, requested, 0, false},
+		{"no exit recorded", config.RestartAlways, nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := NewRestartPolicy(config.RestartConfig{
+				Policy:      tt.policy,
+				MaxRestarts: 5,
+				Window:      300,
+				Backoff:     5,
+				MaxBackoff:  300,
+			})
+
+			delay, ok := policy.Next(&State{LastExit: tt.exit}, now)
+			if ok != tt.wantOK || delay != tt.wantDelay {
+				t.Errorf("Next() = (%v, %v), want (%v, %v)", delay, ok, tt.wantDelay, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRestartPolicyBackoffAndLimit(t *testing.T) {
+	policy := NewRestartPolicy(config.RestartConfig{
+		Policy:      config.RestartOnFailure,
+		MaxRestarts: 4,
+		Window:      300,
+		Backoff:     5,
+		MaxBackoff:  30,
+	})
+
+	now := time.Now()
+	state := &State{LastExit: &ExitInfo{ExitCode: 1, Crashed: true}}
+
+	wantDelays := []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second, 30 * time.Second}
+	for i, want := range wantDelays {
+		delay, ok := policy.Next(state, now)
+		if !ok {
+			t.Fatalf("Next() refused restart #%d", i+1)
+		}
+		if delay != want {
+			t.Errorf("Restart #%d delay = %v, want %v", i+1, delay, want)
+		}
+		recordRestart(state, now, delay)
+	}
+
+	if _, ok := policy.Next(state, now); ok {
+		t.Error("Next() should refuse once MaxRestarts is reached within the window")
+	}
+
+	// Restarts outside the window no longer count
+	later := now.Add(10 * time.Minute)
+	delay, ok := policy.Next(state, later)
+	if !ok || delay != 5*time.Second {
+		t.Errorf("Next() after window = (%v, %v), want (5s, true)", delay, ok)
+	}
+
+	if state.Restarts != 4 || state.Status != StatusRestarting {
+		t.Errorf("State restarts = %d, status = %s", state.Restarts, state.Status)
+	}
+}
+
+func TestRestartPolicyUnboundedBackoff(t *testing.T) {
+	policy := RestartPolicy{Policy: config.RestartAlways, Backoff: 5 * time.Second}
+
+	now := time.Now()
+	state := &State{LastExit: &ExitInfo{ExitCode: 0}}
+
+	wantDelays := []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second, 40 * time.Second}
+	for i, want := range wantDelays {
+		delay, ok := policy.Next(state, now)
+		if !ok || delay != want {
+			t.Errorf("Restart #%d = (%v, %v), want (%v, true)", i+1, delay, ok, want)
+		}
+		recordRestart(state, now, delay)
+	}
+}
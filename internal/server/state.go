@@ -5,16 +5,54 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/jackh54/mcinit/internal/utils"
 )
 
+// Server status values
+const (
+	StatusRunning    = "running"
+	StatusStopping   = "stopping"
+	StatusStopped    = "stopped"
+	StatusCrashed    = "crashed"
+	StatusRestarting = "restarting"
+)
+
 // State represents the server runtime state
 type State struct {
-	PID       int       `json:"pid"`
-	StartTime time.Time `json:"startTime"`
-	Status    string    `json:"status"` // "running", "stopped", "crashed"
+	PID           int       `json:"pid"`
+	SupervisorPID int       `json:"supervisorPid,omitempty"` // mcinit process that owns the server
+	StartTime     time.Time `json:"startTime"`
+	Status        string    `json:"status"` // "running", "stopping", "stopped", "crashed", "restarting"
+	LastExit      *ExitInfo `json:"lastExit,omitempty"`
+
+	// Identify the processes across PID reuse, where supported
+	ProcStartTime       uint64 `json:"procStartTime,omitempty"`
+	Cmdline             string `json:"cmdline,omitempty"`
+	SupervisorStartTime uint64 `json:"supervisorStartTime,omitempty"`
+
+	// Set once the server logs that it is accepting players
+	Ready           bool          `json:"ready,omitempty"`
+	ReadyTime       time.Time     `json:"readyTime,omitempty"`
+	StartupDuration time.Duration `json:"startupDuration,omitempty"`
+
+	// Automatic restarts since the server was last started by hand
+	Restarts      int            `json:"restarts,omitempty"`
+	RestartEvents []RestartEvent `json:"restartEvents,omitempty"`
+	NextRestart   time.Time      `json:"nextRestart,omitempty"`
+}
+
+// ExitInfo describes how the last server run ended
+type ExitInfo struct {
+	ExitCode    int       `json:"exitCode"`
+	Signal      string    `json:"signal,omitempty"`
+	EndTime     time.Time `json:"endTime"`
+	Crashed     bool      `json:"crashed"`
+	Requested   bool      `json:"requested,omitempty"`   // stopped through mcinit
+	CrashReport string    `json:"crashReport,omitempty"` // relative to the server directory
+	Unknown     bool      `json:"unknown,omitempty"`     // the exit status was not observed
 }
 
 // StateFile manages the server state file
@@ -72,6 +110,14 @@ func (s *StateFile) Save(state *State) error {
 		return fmt.Errorf("failed to write state file: %w", err)
 	}
 
+	// The PID file only exists while the server is alive
+	if !state.IsActive() {
+		if err := os.Remove(s.pidPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove PID file: %w", err)
+		}
+		return nil
+	}
+
 	// Write PID file
 	pidData := fmt.Sprintf("%d\n", state.PID)
 	if err := os.WriteFile(s.pidPath, []byte(pidData), 0644); err != nil {
@@ -81,6 +127,34 @@ func (s *StateFile) Save(state *State) error {
 	return nil
 }
 
+// MarkStopping records that a shutdown was requested, so the exit is not
+// mistaken for a crash
+func (s *StateFile) MarkStopping() error {
+	state, err := s.Load()
+	if err != nil {
+		return err
+	}
+
+	state.Status = StatusStopping
+	return s.Save(state)
+}
+
+// MarkStopped records that the server exited when nobody was around to
+// observe its exit status
+func (s *StateFile) MarkStopped() error {
+	state, err := s.Load()
+	if err != nil {
+		return s.Clear()
+	}
+
+	state.Status = StatusStopped
+	state.LastExit = &ExitInfo{
+		EndTime: time.Now(),
+		Unknown: true,
+	}
+	return s.Save(state)
+}
+
 // ReadPID reads the PID from the PID file
 func (s *StateFile) ReadPID() (int, error) {
 	if !utils.PathExists(s.pidPath) {
@@ -124,6 +198,21 @@ func (s *StateFile) Exists() bool {
 	return utils.PathExists(s.statePath) || utils.PathExists(s.pidPath)
 }
 
+// IsActive reports whether the state describes a live server
+func (s *State) IsActive() bool {
+	return s.Status == StatusRunning || s.Status == StatusStopping
+}
+
+// ServerAlive checks that the recorded server process is still running
+func (s *State) ServerAlive() bool {
+	return processMatches(s.PID, s.ProcStartTime, s.Cmdline)
+}
+
+// SupervisorAlive checks that the mcinit process owning the server is still running
+func (s *State) SupervisorAlive() bool {
+	return processMatches(s.SupervisorPID, s.SupervisorStartTime, "")
+}
+
 // GetUptime calculates the server uptime
 func (s *State) GetUptime() time.Duration {
 	if s.StartTime.IsZero() {
@@ -134,14 +223,59 @@ func (s *State) GetUptime() time.Duration {
 
 // FormatUptime returns a human-readable uptime string
 func (s *State) FormatUptime() string {
-	uptime := s.GetUptime()
-	if uptime == 0 {
+	return formatDuration(s.GetUptime())
+}
+
+// FormatStartup returns the startup duration, e.g. "12.3s"
+func (s *State) FormatStartup() string {
+	return fmt.Sprintf("%.1fs", s.StartupDuration.Seconds())
+}
+
+// Summary returns a short description of the exit, e.g.
+// "exit 1, see crash-reports/crash-2025-01-01_12.00.00-server.txt"
+func (e *ExitInfo) Summary() string {
+	var parts []string
+
+	if e.Signal != "" {
+		parts = append(parts, "signal: "+e.Signal)
+	} else if e.Unknown {
+		parts = append(parts, "exit status unknown")
+	} else {
+		parts = append(parts, fmt.Sprintf("exit %d", e.ExitCode))
+	}
+
+	if e.CrashReport != "" {
+		parts = append(parts, "see "+filepath.ToSlash(e.CrashReport))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// FormatAgo returns how long ago the run ended, e.g. "3m ago"
+func (e *ExitInfo) FormatAgo() string {
+	elapsed := time.Since(e.EndTime)
+
+	switch {
+	case elapsed < time.Minute:
+		return fmt.Sprintf("%ds ago", int(elapsed.Seconds()))
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm ago", int(elapsed.Minutes()))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(elapsed.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(elapsed.Hours()/24))
+	}
+}
+
+// formatDuration returns a human-readable duration string
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
 		return "0s"
 	}
 
-	hours := int(uptime.Hours())
-	minutes := int(uptime.Minutes()) % 60
-	seconds := int(uptime.Seconds()) % 60
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	seconds := int(d.Seconds()) % 60
 
 	if hours > 0 {
 		return fmt.Sprintf("%dh %dm %ds", hours, minutes, seconds)
@@ -150,4 +284,3 @@ func (s *State) FormatUptime() string {
 	}
 	return fmt.Sprintf("%ds", seconds)
 }
-
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStateFileSaveAndLoad(t *testing.T) {
+	stateFile := NewStateFile(t.TempDir())
+
+	state := &State{
+		PID:           1234,
+		SupervisorPID: 1200,
+		StartTime:     time.Now(),
+		Status:        StatusRunning,
+	}
+	if err := stateFile.Save(state); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	pid, err := stateFile.ReadPID()
+	if err != nil {
+		t.Fatalf("ReadPID() error = %v", err)
+	}
+	if pid != 1234 {
+		t.Errorf("ReadPID() = %d, want 1234", pid)
+	}
+
+	loaded, err := stateFile.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.SupervisorPID != 1200 {
+		t.Errorf("Loaded SupervisorPID = %d, want 1200", loaded.SupervisorPID)
+	}
+}
+
+func TestStateFileSaveStoppedRemovesPID(t *testing.T) {
+	stateFile := NewStateFile(t.TempDir())
+
+	state := &State{PID: 1234, StartTime: time.Now(), Status: StatusRunning}
+	if err := stateFile.Save(state); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	state.Status = StatusCrashed
+	state.LastExit = &ExitInfo{ExitCode: 1, EndTime: time.Now(), Crashed: true}
+	if err := stateFile.Save(state); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if _, err := stateFile.ReadPID(); err == nil {
+		t.Error("PID file should be removed once the server is no longer active")
+	}
+
+	loaded, err := stateFile.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Status != StatusCrashed {
+		t.Errorf("Loaded status = %s, want %s", loaded.Status, StatusCrashed)
+	}
+	if loaded.LastExit == nil || loaded.LastExit.ExitCode != 1 {
+		t.Errorf("Loaded last exit = %+v, want exit code 1", loaded.LastExit)
+	}
+}
+
+func TestStateFileMarkStopping(t *testing.T) {
+	stateFile := NewStateFile(t.TempDir())
+
+	if err := stateFile.MarkStopping(); err == nil {
+		t.Error("MarkStopping() should fail without a state file")
+	}
+
+	if err := stateFile.Save(&State{PID: 1234, Status: StatusRunning}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := stateFile.MarkStopping(); err != nil {
+		t.Fatalf("MarkStopping() error = %v", err)
+	}
+
+	loaded, err := stateFile.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Status != StatusStopping || !loaded.IsActive() {
+		t.Errorf("Loaded status = %s, want active %s", loaded.Status, StatusStopping)
+	}
+}
+
+func TestStateFileMarkStopped(t *testing.T) {
+	stateFile := NewStateFile(t.TempDir())
+
+	if err := stateFile.Save(&State{PID: 1234, Status: StatusStopping}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := stateFile.MarkStopped(); err != nil {
+		t.Fatalf("MarkStopped() error = %v", err)
+	}
+
+	loaded, err := stateFile.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Status != StatusStopped || loaded.LastExit == nil || loaded.LastExit.Crashed {
+		t.Errorf("Loaded state = %+v, want a clean stop", loaded)
+	}
+	if loaded.LastExit != nil && !loaded.LastExit.Unknown {
+		t.Errorf("LastExit = %+v, want an unknown exit status", loaded.LastExit)
+	}
+	if _, err := stateFile.ReadPID(); err == nil {
+		t.Error("PID file should be removed by MarkStopped()")
+	}
+}
+
+func TestExitInfoSummary(t *testing.T) {
+	tests := []struct {
+		name string
+		exit ExitInfo
+		want string
+	}{
+		{"exit code", ExitInfo{ExitCode: 1}, "exit 1"},
+		{"signal", ExitInfo{ExitCode: -1, Signal: "killed"}, "signal: killed"},
+		{"unknown", ExitInfo{Unknown: true}, "exit status unknown"},
+		{
+			"crash report",
+			ExitInfo{ExitCode: 1, CrashReport: filepath.Join("crash-reports", "crash-server.txt")},
+			"exit 1, see crash-reports/crash-server.txt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.exit.Summary(); got != tt.want {
+				t.Errorf("Summary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitInfoFormatAgo(t *testing.T) {
+	exit := ExitInfo{EndTime: time.Now().Add(-3*time.Minute - 10*time.Second)}
+	if got := exit.FormatAgo(); got != "3m ago" {
+		t.Errorf("FormatAgo() = %q, want %q", got, "3m ago")
+	}
+}
+
+func TestFindCrashReport(t *testing.T) {
+	serverDir := t.TempDir()
+	start := time.Now().Add(-time.Minute)
+
+	if got := findCrashReport(serverDir, start); got != "" {
+		t.Errorf("findCrashReport() = %q, want empty without crash-reports/", got)
+	}
+
+	reportsDir := filepath.Join(serverDir, "crash-reports")
+	if err := os.MkdirAll(reportsDir, 0755); err != nil {
+		t.Fatalf("Failed to create crash-reports: %v", err)
+	}
+
+	old := filepath.Join(reportsDir, "crash-old-server.txt")
+	newer := filepath.Join(reportsDir, "crash-new-server.txt")
+	for _, path := range []string{old, newer} {
+		if err := os.WriteFile(path, []byte("crash"), 0644); err != nil {
+			t.Fatalf("Failed to write crash report: %v", err)
+		}
+	}
+	// The old report predates this run
+	oldTime := start.Add(-time.Hour)
+	if err := os.Chtimes(old, oldTime, oldTime); err != nil {
+		t.Fatalf("Failed to set report time: %v", err)
+	}
+
+	want := filepath.Join("crash-reports", "crash-new-server.txt")
+	if got := findCrashReport(serverDir, start); got != want {
+		t.Errorf("findCrashReport() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackh54/mcinit/internal/utils"
+)
+
+// SupervisorCommand is the hidden mcinit subcommand that runs a supervisor
+const SupervisorCommand = "supervise"
+
+// supervisorStartTimeout is how long Start waits for the supervisor to launch the server
+const supervisorStartTimeout = 15 * time.Second
+
+// ConsoleLogPath returns the path of the console log written by the supervisor
+func ConsoleLogPath(serverDir string) string {
+	return filepath.Join(serverDir, ".mcinit", "console.log")
+}
+
+// SupervisorLogPath returns the path of the supervisor's own log
+func SupervisorLogPath(serverDir string) string {
+	return filepath.Join(serverDir, ".mcinit", "supervisor.log")
+}
+
+// spawnSupervisor re-executes mcinit as a detached supervisor that owns the
+// server process, and waits until it has started the server
+func spawnSupervisor(serverDir, extraArgs string) (int, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to locate mcinit executable: %w", err)
+	}
+
+	if err := utils.EnsureDir(filepath.Join(serverDir, ".mcinit")); err != nil {
+		return 0, fmt.Errorf("failed to create .mcinit directory: %w", err)
+	}
+
+	logPath := SupervisorLogPath(serverDir)
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open supervisor log: %w", err)
+	}
+	defer func() { _ = logFile.Close() }()
+
+	args := []string{SupervisorCommand}
+	if extraArgs != "" {
+		args = append(args, "--args", extraArgs)
+	}
+
+	cmd := exec.Command(executable, args...)
+	cmd.Dir = serverDir
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = detachedProcAttr()
+
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start supervisor: %w", err)
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+
+	// Wait for the supervisor to record the server process
+	stateFile := NewStateFile(serverDir)
+	deadline := time.After(supervisorStartTimeout)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-exited:
+			return 0, fmt.Errorf("supervisor exited before the server started%s", logTail(logPath, 10))
+		case <-deadline:
+			return 0, fmt.Errorf("timed out waiting for supervisor to start the server%s", logTail(logPath, 10))
+		case <-ticker.C:
+			state, err := stateFile.Load()
+			if err == nil && state.SupervisorPID == cmd.Process.Pid && state.IsActive() {
+				return state.PID, nil
+			}
+		}
+	}
+}
+
+// logTail returns the last lines of a log file formatted for an error message
+func logTail(path string, n int) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	if len(lines) == 0 || (len(lines) == 1 && lines[0] == "") {
+		return ""
+	}
+
+	return ":\n  " + strings.Join(lines, "\n  ")
+}
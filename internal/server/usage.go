@@ -0,0 +1,98 @@
+package server
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jackh54/mcinit/pkg/jvmflags"
+)
+
+// ErrUsageUnsupported is returned where resource usage cannot be read
+var ErrUsageUnsupported = errors.New("resource usage is not supported on this platform")
+
+// usageBaselineInterval is how long the first sample waits to measure CPU usage
+const usageBaselineInterval = 500 * time.Millisecond
+
+// ResourceUsage is the resource usage of the server's process group
+type ResourceUsage struct {
+	CPUPercent float64 // percent of one core since the previous sample
+	RSS        uint64  // resident memory in bytes
+	Threads    int
+	OpenFiles  int
+	Processes  int
+	MaxHeap    uint64 // configured -Xmx in bytes, 0 if unknown
+}
+
+// groupUsage is a snapshot of the counters of a process group
+type groupUsage struct {
+	cpuSeconds float64
+	rss        uint64
+	threads    int
+	openFiles  int
+	processes  int
+}
+
+// UsageSampler measures the resource usage of a process group. CPU usage is
+// the average between consecutive samples.
+type UsageSampler struct {
+	pgid     int
+	maxHeap  uint64
+	lastCPU  float64
+	lastTime time.Time
+}
+
+// NewUsageSampler creates a UsageSampler for the process group pgid
+func NewUsageSampler(pgid int) *UsageSampler {
+	return &UsageSampler{pgid: pgid}
+}
+
+// Sample reads the current resource usage. The first sample blocks briefly
+// to have a baseline for CPU usage.
+func (s *UsageSampler) Sample() (*ResourceUsage, error) {
+	if s.lastTime.IsZero() {
+		group, err := readGroupUsage(s.pgid)
+		if err != nil {
+			return nil, err
+		}
+		s.lastCPU, s.lastTime = group.cpuSeconds, time.Now()
+		time.Sleep(usageBaselineInterval)
+	}
+
+	group, err := readGroupUsage(s.pgid)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	usage := &ResourceUsage{
+		RSS:       group.rss,
+		Threads:   group.threads,
+		OpenFiles: group.openFiles,
+		Processes: group.processes,
+		MaxHeap:   s.maxHeap,
+	}
+	if elapsed := now.Sub(s.lastTime).Seconds(); elapsed > 0 && group.cpuSeconds >= s.lastCPU {
+		usage.CPUPercent = (group.cpuSeconds - s.lastCPU) / elapsed * 100
+	}
+
+	s.lastCPU, s.lastTime = group.cpuSeconds, now
+	return usage, nil
+}
+
+// UsageSampler returns a sampler for the running server. The server is
+// started in its own process group, led by the Java process.
+func (m *Manager) UsageSampler() (*UsageSampler, error) {
+	state, err := m.process.GetState()
+	if err != nil {
+		return nil, err
+	}
+	if !m.process.IsRunning() {
+		return nil, errors.New("server is not running")
+	}
+
+	sampler := NewUsageSampler(state.PID)
+	if m.config != nil {
+		sampler.maxHeap, _ = jvmflags.ParseMemory(m.config.JVM.Xmx)
+	}
+	return sampler, nil
+}
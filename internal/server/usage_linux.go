@@ -0,0 +1,81 @@
+//go:build linux
+
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// clockTicksPerSecond is USER_HZ, the unit of CPU times in /proc. It is 100
+// on all Linux architectures Go supports.
+const clockTicksPerSecond = 100
+
+// readGroupUsage sums the resource usage of all processes in a process group
+func readGroupUsage(pgid int) (*groupUsage, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read /proc: %w", err)
+	}
+
+	group := &groupUsage{}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		// Processes may exit while they are being read
+		stat, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		if pgrp, err := statUint(string(stat), 5); err != nil || int(pgrp) != pgid {
+			continue
+		}
+
+		utime, _ := statUint(string(stat), 14)
+		stime, _ := statUint(string(stat), 15)
+		threads, _ := statUint(string(stat), 20)
+
+		group.processes++
+		group.cpuSeconds += float64(utime+stime) / clockTicksPerSecond
+		group.threads += int(threads)
+		group.rss += readRSS(pid)
+		if fds, err := os.ReadDir(filepath.Join("/proc", entry.Name(), "fd")); err == nil {
+			group.openFiles += len(fds)
+		}
+	}
+
+	if group.processes == 0 {
+		return nil, fmt.Errorf("no processes found in process group %d", pgid)
+	}
+	return group, nil
+}
+
+// readRSS reads the resident memory of a process from /proc/<pid>/status
+func readRSS(pid int) uint64 {
+	file, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return 0
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// VmRSS:    123456 kB
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "VmRSS:" {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0
+			}
+			return kb * 1024
+		}
+	}
+	return 0
+}
@@ -0,0 +1,53 @@
+//go:build linux
+
+package server
+
+import (
+	"os/exec"
+	"syscall"
+	"testing"
+)
+
+func TestUsageSamplerProcessGroup(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("Failed to start sleep: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	usage, err := NewUsageSampler(cmd.Process.Pid).Sample()
+	if err != nil {
+		t.Fatalf("Sample() error = %v", err)
+	}
+
+	if usage.Processes != 1 {
+		t.Errorf("Sample() processes = %d, want 1", usage.Processes)
+	}
+	if usage.Threads < 1 {
+		t.Errorf("Sample() threads = %d, want at least 1", usage.Threads)
+	}
+	if usage.RSS == 0 {
+		t.Error("Sample() RSS should not be zero")
+	}
+	if usage.OpenFiles == 0 {
+		t.Error("Sample() open files should not be zero")
+	}
+	if usage.CPUPercent < 0 {
+		t.Errorf("Sample() CPU = %.1f%%, want >= 0", usage.CPUPercent)
+	}
+}
+
+func TestUsageSamplerMissingGroup(t *testing.T) {
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("Failed to run true: %v", err)
+	}
+
+	if _, err := NewUsageSampler(cmd.Process.Pid).Sample(); err == nil {
+		t.Error("Sample() should fail for a process group that no longer exists")
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package server
+
+// readGroupUsage is only implemented on Linux
+func readGroupUsage(pgid int) (*groupUsage, error) {
+	return nil, ErrUsageUnsupported
+}
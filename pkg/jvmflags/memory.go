@@ -0,0 +1,38 @@
+package jvmflags
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseMemory converts a JVM memory size such as "4G", "512m" or "1024K"
+// to bytes, using the same binary units as -Xms and -Xmx
+func ParseMemory(size string) (uint64, error) {
+	size = strings.TrimSpace(size)
+	if size == "" {
+		return 0, fmt.Errorf("empty memory size")
+	}
+
+	multiplier := uint64(1)
+	switch size[len(size)-1] {
+	case 'k', 'K':
+		multiplier = 1 << 10
+	case 'm', 'M':
+		multiplier = 1 << 20
+	case 'g', 'G':
+		multiplier = 1 << 30
+	case 't', 'T':
+		multiplier = 1 << 40
+	}
+	if multiplier != 1 {
+		size = size[:len(size)-1]
+	}
+
+	value, err := strconv.ParseUint(size, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory size: %s", size)
+	}
+
+	return value * multiplier, nil
+}
@@ -0,0 +1,33 @@
+package jvmflags
+
+import "testing"
+
+func TestParseMemory(t *testing.T) {
+	tests := []struct {
+		size    string
+		want    uint64
+		wantErr bool
+	}{
+		{"4G", 4 << 30, false},
+		{"512m", 512 << 20, false},
+		{"1024K", 1 << 20, false},
+		{"1T", 1 << 40, false},
+		{"1048576", 1 << 20, false},
+		{"", 0, true},
+		{"G", 0, true},
+		{"4GB", 0, true},
+		{"-1G", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			got, err := ParseMemory(tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMemory(%q) error = %v, wantErr %v", tt.size, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMemory(%q) = %d, want %d", tt.size, got, tt.want)
+			}
+		})
+	}
+}